@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"ilaunch/internal/system"
+
+	"github.com/spf13/cobra"
+)
+
+var doctorJSON bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the machine and project setup",
+	Long: "Run every environment check independently and report pass, warn or fail for each,\n" +
+		"with a hint on how to fix it. Exits with code 1 when a check fails.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := system.Doctor(context.Background(), system.ExecCommander{}, ".")
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		if doctorJSON {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			enc.SetEscapeHTML(false)
+			if err = enc.Encode(report); err != nil {
+				return fmt.Errorf("encode report: %w", err)
+			}
+		} else {
+			printReport(out, report)
+		}
+		if report.Status == system.StatusFail {
+			return exitCodeError{code: 1, err: fmt.Errorf("doctor found failing checks")}
+		}
+		return nil
+	},
+}
+
+func printReport(w io.Writer, r system.Report) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAIL")
+	counts := map[system.Status]int{}
+	for _, c := range r.Checks {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Check, c.Status, c.Detail)
+		counts[c.Status]++
+	}
+	tw.Flush()
+
+	hints := false
+	for _, c := range r.Checks {
+		if c.Hint == "" {
+			continue
+		}
+		if !hints {
+			fmt.Fprintln(w, "\nhints:")
+			hints = true
+		}
+		fmt.Fprintf(w, "  %s: %s\n", c.Check, c.Hint)
+	}
+	summary := fmt.Sprintf("%d pass, %d warn, %d fail", counts[system.StatusPass], counts[system.StatusWarn], counts[system.StatusFail])
+	if n := counts[system.StatusSkip]; n > 0 {
+		summary += fmt.Sprintf(", %d skipped", n)
+	}
+	fmt.Fprintln(w, "\n"+summary)
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "Print the report as JSON")
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"ilaunch/internal/env"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	envExamplePath  string
+	envFilePath     string
+	diffFormat      string
+	diffStrict      bool
+	restoreList     bool
+	exportFormat    string
+	exportOutput    string
+	exportExpand    bool
+	exportOpts      env.ExportOptions
+	scanDryRun      bool
+	envKeyFile      string
+	keygenForce     bool
+	encryptPerValue bool
+	cryptPath       string
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Inspect and manage .env files",
+}
+
+var envDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Report drift between .env and .env.example",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		template, err := env.ReadDocument(envExamplePath)
+		if err != nil {
+			return err
+		}
+		current, err := env.ReadDocument(envFilePath)
+		if err != nil {
+			return err
+		}
+		drift := env.Diff(template, current.Entries())
+		out := cmd.OutOrStdout()
+		switch diffFormat {
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			if err = enc.Encode(drift); err != nil {
+				return fmt.Errorf("encode drift: %w", err)
+			}
+		case "text":
+			printDrift(out, drift)
+		default:
+			return fmt.Errorf("unknown format %q (expected text or json)", diffFormat)
+		}
+		if drift.HasDrift(diffStrict) {
+			return exitCodeError{code: 1, err: fmt.Errorf("%s has drifted from %s", envFilePath, envExamplePath)}
+		}
+		return nil
+	},
+}
+
+var envRestoreCmd = &cobra.Command{
+	Use:   "restore [backup]",
+	Short: "Restore .env from a backup (latest by default)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		backups, err := env.ListBackups(envFilePath)
+		if err != nil {
+			return err
+		}
+		if restoreList {
+			if len(backups) == 0 {
+				fmt.Fprintf(out, "no backups of %s\n", envFilePath)
+			}
+			for _, b := range backups {
+				note := ""
+				if b.Restore {
+					note = "  (replaced by a restore)"
+				}
+				fmt.Fprintf(out, "%s  %s%s\n", b.Time.Format("2006-01-02 15:04:05"), b.Path, note)
+			}
+			return nil
+		}
+		var source string
+		switch {
+		case len(args) == 1:
+			source = args[0]
+		default:
+			latest, ok := env.LatestBackup(backups)
+			if !ok {
+				return fmt.Errorf("no backups of %s to restore", envFilePath)
+			}
+			source = latest.Path
+		}
+		previous, err := env.Restore(envFilePath, source, time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "restored %s from %s\n", envFilePath, source)
+		if previous != "" {
+			fmt.Fprintf(out, "previous %s saved to %s\n", envFilePath, previous)
+		}
+		return nil
+	},
+}
+
+var envExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export .env values as JSON, shell, docker env-file or Kubernetes manifests",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exporter, err := env.NewExporter(exportFormat, exportOpts)
+		if err != nil {
+			return err
+		}
+		doc, err := env.ReadDocument(envFilePath)
+		if err != nil {
+			return err
+		}
+		values := make(map[string]string)
+		for _, e := range doc.Entries() {
+			values[e.Key] = e.Default
+		}
+		if exportExpand {
+			if values, err = doc.Expand(nil, nil); err != nil {
+				return err
+			}
+		}
+		var buf bytes.Buffer
+		if err = exporter.Export(&buf, doc.Vars(values)); err != nil {
+			return fmt.Errorf("export %s: %w", exportFormat, err)
+		}
+		if exportOutput == "" {
+			_, err = cmd.OutOrStdout().Write(buf.Bytes())
+			return err
+		}
+		if err = os.WriteFile(exportOutput, buf.Bytes(), 0o600); err != nil {
+			return fmt.Errorf("write %s: %w", exportOutput, err)
+		}
+		return nil
+	},
+}
+
+var envScanCmd = &cobra.Command{
+	Use:   "scan [dir]",
+	Short: "Add env variables used in JS/TS sources to .env.example",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		root := "."
+		if len(args) == 1 {
+			root = args[0]
+		}
+		refs, err := env.ScanSource(root)
+		if err != nil {
+			return err
+		}
+		doc, err := env.ReadDocument(envExamplePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			doc, err = &env.Document{}, nil
+		}
+		if err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		added := doc.AddDiscovered(refs)
+		fmt.Fprintf(out, "found %d variables, %d not documented in %s\n", len(refs), len(added), envExamplePath)
+		for _, r := range refs {
+			if slices.Contains(added, r.Key) {
+				fmt.Fprintf(out, "  + %s (%s:%d)\n", r.Key, r.File, r.Line)
+			}
+		}
+		if len(added) == 0 || scanDryRun {
+			return nil
+		}
+		if err = env.WriteAtomicMode(envExamplePath, doc.Render(nil), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "updated %s; review the new entries marked TODO\n", envExamplePath)
+		return nil
+	},
+}
+
+var envKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Create a local key for encrypting .env files",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := os.Stat(envKeyFile); err == nil && !keygenForce {
+			return fmt.Errorf("%s already exists (use --force to replace it)", envKeyFile)
+		}
+		key, err := env.GenerateKey()
+		if err != nil {
+			return err
+		}
+		if err = env.WriteAtomic(envKeyFile, []byte(env.EncodeKey(key)+"\n")); err != nil {
+			return err
+		}
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "wrote %s; share it as %s in CI\n", envKeyFile, env.KeyEnvVar)
+		added, err := env.EnsureIgnored(env.GitignoreFile, env.SecretPatterns(envFilePath, envKeyFile)...)
+		if err != nil {
+			return err
+		}
+		if len(added) > 0 {
+			fmt.Fprintf(out, "added %s to %s\n", strings.Join(added, ", "), env.GitignoreFile)
+		}
+		return nil
+	},
+}
+
+var envEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt .env into .env.enc so it can be committed",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := env.LoadKey(envKeyFile)
+		if err != nil {
+			return err
+		}
+		plain, err := os.ReadFile(envFilePath)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", envFilePath, err)
+		}
+		var sealed []byte
+		if encryptPerValue {
+			doc, err := env.ParseDocument(bytes.NewReader(plain))
+			if err != nil {
+				return fmt.Errorf("parse %s: %w", envFilePath, err)
+			}
+			sealed, err = env.EncryptValues(doc, key)
+			if err != nil {
+				return err
+			}
+		} else if sealed, err = env.EncryptFile(plain, key); err != nil {
+			return err
+		}
+		target := cryptPath
+		if target == "" {
+			target = env.EncryptedFile(envFilePath)
+		}
+		if err = env.WriteAtomic(target, sealed); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "encrypted %s to %s\n", envFilePath, target)
+		return nil
+	},
+}
+
+var envDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt .env.enc into .env",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := env.LoadKey(envKeyFile)
+		if err != nil {
+			return err
+		}
+		source := cryptPath
+		if source == "" {
+			source = env.EncryptedFile(envFilePath)
+		}
+		sealed, err := os.ReadFile(source)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", source, err)
+		}
+		plain, err := env.Decrypt(sealed, key)
+		if err != nil {
+			return fmt.Errorf("decrypt %s: %w", source, err)
+		}
+		out := cmd.OutOrStdout()
+		backup, err := env.BackupFile(envFilePath, time.Now())
+		if err != nil {
+			return err
+		}
+		if backup != "" {
+			fmt.Fprintf(out, "previous %s saved to %s\n", envFilePath, backup)
+		}
+		if err = env.WriteAtomic(envFilePath, plain); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "decrypted %s to %s\n", source, envFilePath)
+		return nil
+	},
+}
+
+func printDrift(w io.Writer, d env.Drift) {
+	if len(d.Missing)+len(d.Extra)+len(d.Placeholder) == 0 {
+		fmt.Fprintf(w, "no drift between %s and %s\n", envFilePath, envExamplePath)
+		return
+	}
+	section := func(title string, keys []string) {
+		if len(keys) == 0 {
+			return
+		}
+		fmt.Fprintln(w, title)
+		for _, k := range keys {
+			fmt.Fprintf(w, "  - %s\n", k)
+		}
+	}
+	section(fmt.Sprintf("missing (in %s, not in %s):", envExamplePath, envFilePath), d.Missing)
+	section(fmt.Sprintf("extra (in %s, not in %s):", envFilePath, envExamplePath), d.Extra)
+	section(fmt.Sprintf("placeholder (still set to the %s value):", envExamplePath), d.Placeholder)
+}
+
+func init() {
+	envCmd.PersistentFlags().StringVar(&envExamplePath, "example", ".env.example", "Path to the .env.example template")
+	envCmd.PersistentFlags().StringVar(&envFilePath, "file", ".env", "Path to the .env file")
+	envDiffCmd.Flags().StringVar(&diffFormat, "format", "text", "Output format: text or json")
+	envDiffCmd.Flags().BoolVar(&diffStrict, "strict", false, "Treat placeholder values as drift")
+	envRestoreCmd.Flags().BoolVar(&restoreList, "list", false, "List available backups instead of restoring")
+	envExportCmd.Flags().StringVar(&exportFormat, "format", "json", "Output format: "+strings.Join(env.ExportFormats(), ", "))
+	envExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write to a file instead of stdout")
+	envExportCmd.Flags().BoolVar(&exportExpand, "expand", true, "Resolve ${VAR} references before exporting")
+	envExportCmd.Flags().StringVar(&exportOpts.Name, "name", "app-env", "Kubernetes object name")
+	envExportCmd.Flags().StringVar(&exportOpts.Namespace, "namespace", "", "Kubernetes namespace")
+	envScanCmd.Flags().BoolVar(&scanDryRun, "dry-run", false, "Report undocumented variables without writing")
+	envCmd.PersistentFlags().StringVar(&envKeyFile, "key-file", env.DefaultKeyFile, "Encryption key file (overridden by $"+env.KeyEnvVar+")")
+	envKeygenCmd.Flags().BoolVar(&keygenForce, "force", false, "Replace an existing key file")
+	envEncryptCmd.Flags().BoolVar(&encryptPerValue, "per-value", false, "Encrypt each value separately, keeping keys and comments readable")
+	envEncryptCmd.Flags().StringVarP(&cryptPath, "output", "o", "", "Encrypted file (default <file>.enc)")
+	envDecryptCmd.Flags().StringVarP(&cryptPath, "input", "i", "", "Encrypted file (default <file>.enc)")
+	envCmd.AddCommand(envDiffCmd, envRestoreCmd, envExportCmd, envScanCmd, envKeygenCmd, envEncryptCmd, envDecryptCmd)
+	rootCmd.AddCommand(envCmd)
+}
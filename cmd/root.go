@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"ilaunch/internal/app"
+	"ilaunch/internal/env"
 
 	"github.com/spf13/cobra"
 )
@@ -18,7 +19,10 @@ func (e exitCodeError) Error() string { return e.err.Error() }
 func (e exitCodeError) Unwrap() error { return e.err }
 func (e exitCodeError) ExitCode() int { return e.code }
 
-var nonInteractive bool
+var (
+	nonInteractive bool
+	runOpts        app.Options
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "ilaunch",
@@ -28,9 +32,9 @@ var rootCmd = &cobra.Command{
 		var code int
 		var err error
 		if nonInteractive {
-			code, err = app.RunNonInteractive(ctx)
+			code, err = app.RunNonInteractive(ctx, runOpts)
 		} else {
-			code, err = app.RunInteractive(ctx)
+			code, err = app.RunInteractive(ctx, runOpts)
 		}
 		if err != nil {
 			return exitCodeError{code: code, err: err}
@@ -48,7 +52,16 @@ func Execute() error {
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&nonInteractive, "non-interactive", false, "Run without TUI (CI mode)")
+	rootCmd.Flags().BoolVar(&runOpts.ExpandEnv, "expand", false, "Write .env with ${VAR} references expanded")
+	rootCmd.Flags().BoolVar(&runOpts.ExpandFromOS, "expand-from-env", false, "Resolve ${VAR} references from the process environment too")
+	rootCmd.Flags().StringVar(&runOpts.Profile, "profile", "", "Generate .env.<profile> using the .env.example.<profile> overlay")
+	rootCmd.Flags().BoolVar(&runOpts.Backup, "backup", true, "Back up an existing .env before rewriting it")
+	rootCmd.Flags().StringVar(&runOpts.KeyFile, "key-file", env.DefaultKeyFile, "Key for decrypting a committed .env.enc (overridden by $"+env.KeyEnvVar+")")
+	rootCmd.Flags().BoolVar(&runOpts.Corepack, "corepack", false, "Provision the package manager pinned in package.json with corepack when the installed version differs")
+	rootCmd.Flags().BoolVar(&runOpts.UseVersionManager, "use-version-manager", false, "Switch to, or install, a node that satisfies the project through nvm, fnm, volta, asdf or mise in non-interactive mode")
+	rootCmd.Flags().BoolVar(&runOpts.FrozenLockfile, "frozen-lockfile", false, "Fail instead of updating the lockfile during install (npm ci, --immutable for Yarn 2+)")
 	rootCmd.SilenceUsage = true
+	rootCmd.SilenceErrors = true
 }
 
 func ExitCode(err error) int {
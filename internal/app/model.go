@@ -3,12 +3,15 @@ package app
 import (
 	"context"
 	"fmt"
+	"maps"
 	"os"
-	"path/filepath"
+	"slices"
+	"strings"
 
 	"ilaunch/internal/env"
 	"ilaunch/internal/runner"
 	"ilaunch/internal/system"
+	"ilaunch/internal/ui/components"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -24,7 +27,11 @@ type Screen int
 
 const (
 	ScreenMenu Screen = iota
+	ScreenToolchain
+	ScreenProvision
+	ScreenProfile
 	ScreenEnvForm
+	ScreenEnvReview
 	ScreenLogs
 	ScreenError
 )
@@ -33,13 +40,24 @@ type ProcessMsg struct{ Event runner.Event }
 type ErrorMsg struct{ Err error }
 type ProgressMsg struct{ Value float64 }
 
+// CheckMsg carries the result of re-running the environment checks.
+type CheckMsg struct {
+	Result system.CheckResult
+	Err    error
+}
+
 type Model struct {
-	screen      Screen
-	menuIndex   int
-	envEntries  []env.Entry
-	envValues   map[string]string
-	fieldIndex  int
-	fieldInput  string
+	screen     Screen
+	menuIndex  int
+	profiles   []profileChoice
+	profileIdx int
+	envPlan    envPlan
+	envEntries []env.Entry
+	fieldIndex int
+	// fieldInputs, fieldErrs and fieldWarns are indexed like envEntries.
+	fieldInputs []components.Field
+	fieldErrs   []string
+	fieldWarns  [][]string
 	logs        []string
 	scroll      int
 	progress    float64
@@ -48,22 +66,34 @@ type Model struct {
 	width       int
 	height      int
 	checkResult system.CheckResult
-	runner      runner.Runner
-	processCh   <-chan runner.Event
-	ctx         context.Context
-	cancel      context.CancelFunc
-	exitCode    int
-	pending     [][]string
+	// toolchains are offered when the node on PATH fails the project's
+	// requirement; toolchain is the one selected.
+	nodeErr      error
+	toolchains   []toolchainChoice
+	toolchainIdx int
+	toolchain    *system.Toolchain
+	// provision is the pinned package manager offered for corepack
+	// provisioning; recheck re-runs the checks once the queue finishes.
+	provision    *system.Provision
+	provisioning bool
+	recheck      bool
+	opts         Options
+	runner       runner.Runner
+	processCh    <-chan runner.Event
+	ctx          context.Context
+	cancel       context.CancelFunc
+	exitCode     int
+	pending      [][]string
 }
 
-func NewModel(check system.CheckResult) Model {
+func NewModel(check system.CheckResult, opts Options) Model {
 	ctx, cancel := context.WithCancel(context.Background())
 	return Model{
 		screen:      ScreenMenu,
-		envValues:   map[string]string{},
 		width:       defaultWinWidth,
 		height:      defaultWinHeight,
 		checkResult: check,
+		opts:        opts,
 		runner:      runner.Runner{},
 		ctx:         ctx,
 		cancel:      cancel,
@@ -75,7 +105,7 @@ func (m Model) Init() tea.Cmd {
 }
 
 func (m *Model) addLog(line string) {
-	m.logs = append(m.logs, line)
+	m.logs = append(m.logs, m.runner.Redact(line))
 	if len(m.logs) > maxLogLines {
 		m.logs = m.logs[len(m.logs)-maxLogLines:]
 	}
@@ -85,6 +115,15 @@ func (m *Model) setError(err error) {
 	m.err = err
 	m.screen = ScreenError
 	m.exitCode = 1
+	m.resetQueue()
+}
+
+// resetQueue drops the queued commands and the provisioning state, so an
+// aborted run does not leak into the next one.
+func (m *Model) resetQueue() {
+	m.pending = nil
+	m.recheck = false
+	m.provisioning = false
 }
 
 func (m *Model) startProcess(name string, args ...string) tea.Cmd {
@@ -106,30 +145,244 @@ func waitProcessEvent(ch <-chan runner.Event) tea.Cmd {
 	}
 }
 
-func (m *Model) beginCreateEnv() tea.Cmd {
-	examplePath := filepath.Join(".env.example")
-	file, err := os.Open(examplePath)
+// profileChoice is an entry of the profile picker. overlay is set when an
+// .env.example.<profile> file exists for it.
+type profileChoice struct {
+	name    string
+	overlay bool
+}
+
+var commonProfiles = []string{"", "local", "development", "test", "production"}
+
+func (m *Model) beginPickProfile() tea.Cmd {
+	discovered, err := env.DiscoverProfiles(".")
 	if err != nil {
-		m.setError(fmt.Errorf("open .env.example: %w", err))
+		m.setError(err)
 		return nil
 	}
-	defer file.Close()
-	entries, err := env.ParseExample(file)
+	names := append([]string{}, commonProfiles...)
+	for _, p := range append(discovered, m.opts.Profile) {
+		if !slices.Contains(names, p) {
+			names = append(names, p)
+		}
+	}
+	m.profiles = make([]profileChoice, 0, len(names))
+	m.profileIdx = 0
+	for i, name := range names {
+		m.profiles = append(m.profiles, profileChoice{name: name, overlay: slices.Contains(discovered, name)})
+		if name == m.opts.Profile {
+			m.profileIdx = i
+		}
+	}
+	m.screen = ScreenProfile
+	return nil
+}
+
+func (m *Model) beginCreateEnv() tea.Cmd {
+	plan, err := planEnv(m.opts)
 	if err != nil {
-		m.setError(fmt.Errorf("parse .env.example: %w", err))
+		m.setError(err)
 		return nil
 	}
-	m.envEntries = entries
-	m.envValues = make(map[string]string, len(entries))
+	m.envPlan = plan
+	m.envEntries = plan.fields
 	m.fieldIndex = 0
-	m.fieldInput = ""
-	if len(entries) > 0 {
-		m.fieldInput = entries[0].Default
+	if len(plan.fields) == 0 {
+		if !plan.existing {
+			m.setError(fmt.Errorf("%s has no entries", env.ExampleFile))
+			return nil
+		}
+		for _, line := range plan.summary() {
+			m.addLog(line)
+		}
+		m.screen = ScreenLogs
+		return nil
+	}
+	m.fieldInputs = make([]components.Field, len(plan.fields))
+	m.fieldErrs = make([]string, len(plan.fields))
+	m.fieldWarns = make([][]string, len(plan.fields))
+	for i := range plan.fields {
+		if err := m.resetField(i); err != nil {
+			m.setError(err)
+			return nil
+		}
 	}
 	m.screen = ScreenEnvForm
 	return nil
 }
 
+// resetField puts the default back into a field, generating a fresh value
+// for entries with @generate. Entries with a fixed set of values get a
+// select instead of a text input.
+func (m *Model) resetField(index int) error {
+	entry := m.envEntries[index]
+	value, err := entry.Value()
+	if err != nil {
+		return err
+	}
+	if choices := entry.Choices(); choices != nil {
+		sel, err := components.NewSelect(choices, entry.Normalize(value))
+		if err != nil {
+			return fmt.Errorf("%s default: %w", entry.Key, err)
+		}
+		m.fieldInputs[index] = &sel
+	} else {
+		input := components.NewTextInput(value, entry.IsSecret())
+		m.fieldInputs[index] = &input
+	}
+	m.fieldErrs[index] = ""
+	m.fieldWarns[index] = nil
+	return nil
+}
+
+func (m Model) fieldValue(index int) string {
+	return m.envEntries[index].Normalize(strings.TrimSpace(m.fieldInputs[index].Value()))
+}
+
+// formValues returns the plan values with the form's current inputs.
+func (m Model) formValues() map[string]string {
+	values := make(map[string]string, len(m.envPlan.values)+len(m.envEntries))
+	maps.Copy(values, m.envPlan.values)
+	for i, e := range m.envEntries {
+		values[e.Key] = m.fieldValue(i)
+	}
+	return values
+}
+
+// checkField validates a field and records its error and warnings for the
+// form to show inline. A value such as http://localhost:${PORT} is checked
+// as what it resolves to, like createEnvWithDefaults does.
+func (m *Model) checkField(index int) bool {
+	entry := m.envEntries[index]
+	m.fieldErrs[index] = ""
+	m.fieldWarns[index] = nil
+	expanded, err := m.envPlan.document().ExpandKeys(m.formValues(), m.envPlan.lookup, []string{entry.Key})
+	if err != nil {
+		m.fieldErrs[index] = err.Error()
+		return false
+	}
+	value := expanded[entry.Key]
+	if err = entry.Validate(value); err != nil {
+		m.fieldErrs[index] = err.Error()
+		return false
+	}
+	m.fieldWarns[index] = entry.Warnings(value)
+	return true
+}
+
+func (m *Model) focusField(index int) {
+	m.fieldIndex = max(0, min(index, len(m.envEntries)-1))
+}
+
+// beginReview validates every field and opens the review screen, or jumps
+// to the first invalid field.
+func (m *Model) beginReview() {
+	first := -1
+	for i := range m.envEntries {
+		if !m.checkField(i) && first < 0 {
+			first = i
+		}
+	}
+	if first >= 0 {
+		m.focusField(first)
+		return
+	}
+	m.screen = ScreenEnvReview
+}
+
+func (m *Model) submitEnvForm() {
+	for i, e := range m.envEntries {
+		m.envPlan.values[e.Key] = m.fieldValue(i)
+		for _, w := range m.fieldWarns[i] {
+			m.envPlan.warnings = append(m.envPlan.warnings, fmt.Sprintf("warning: %s: %s", e.Key, w))
+		}
+	}
+	if err := m.envPlan.write(); err != nil {
+		m.setError(err)
+		return
+	}
+	m.runner.Secrets = m.envPlan.secrets()
+	for _, line := range m.envPlan.summary() {
+		m.addLog(line)
+	}
+	m.screen = ScreenMenu
+}
+
+func (m *Model) beginProvision() tea.Cmd {
+	cmds, err := system.ProvisionCommands(system.ExecCommander{Env: m.runner.Env}, *m.provision)
+	if err != nil {
+		m.setError(err)
+		return nil
+	}
+	m.enqueue(cmds...)
+	m.provisioning = true
+	m.recheck = true
+	return m.startNextQueued()
+}
+
+// toolchainChoice is an entry of the node picker: an installed node to
+// switch to, or the command that installs one.
+type toolchainChoice struct {
+	label     string
+	toolchain *system.Toolchain
+	install   []string
+}
+
+func toolchainChoices(constraints []system.NodeConstraint) ([]toolchainChoice, error) {
+	managers := system.DetectVersionManagers(system.ExecCommander{})
+	if len(managers) == 0 {
+		return nil, errNoVersionManager
+	}
+	matches, err := system.SatisfyingToolchains(managers, constraints)
+	if err != nil {
+		return nil, err
+	}
+	choices := make([]toolchainChoice, 0, len(matches)+len(managers))
+	for i := range matches {
+		choices = append(choices, toolchainChoice{label: "Use " + matches[i].String(), toolchain: &matches[i]})
+	}
+	if target, ok := system.InstallTarget(constraints); ok {
+		for _, vm := range managers {
+			choices = append(choices, toolchainChoice{label: fmt.Sprintf("Install node %s with %s", target, vm.Name), install: vm.InstallCommand(target)})
+		}
+	}
+	if len(choices) == 0 {
+		return nil, fmt.Errorf("no node release satisfies the project requirements")
+	}
+	return choices, nil
+}
+
+func (m *Model) offerToolchains(err error, choices []toolchainChoice) {
+	m.nodeErr = err
+	m.toolchains = choices
+	m.toolchainIdx = 0
+	m.screen = ScreenToolchain
+}
+
+// chooseToolchain switches to the selected node, or installs one and
+// offers the choice again once it is there.
+func (m *Model) chooseToolchain() tea.Cmd {
+	choice := m.toolchains[m.toolchainIdx]
+	m.resetQueue()
+	if choice.toolchain == nil {
+		m.enqueue(choice.install)
+		m.recheck = true
+		return m.startNextQueued()
+	}
+	m.toolchain = choice.toolchain
+	m.runner.Env = choice.toolchain.Env(os.Environ())
+	m.screen = ScreenLogs
+	m.addLog("switching to " + choice.toolchain.String())
+	return recheckEnvironment(m.ctx, m.toolchain)
+}
+
+func recheckEnvironment(ctx context.Context, tc *system.Toolchain) tea.Cmd {
+	return func() tea.Msg {
+		res, err := checkEnvironment(ctx, tc)
+		return CheckMsg{Result: res, Err: err}
+	}
+}
+
 func (m *Model) enqueue(commands ...[]string) {
 	m.pending = append(m.pending, commands...)
 }
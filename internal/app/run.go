@@ -2,8 +2,13 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"maps"
 	"os"
+	"strings"
+	"time"
 
 	"ilaunch/internal/env"
 	"ilaunch/internal/runner"
@@ -12,12 +17,56 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-func RunInteractive(ctx context.Context) (int, error) {
-	check, err := system.CheckEnvironment(ctx, system.ExecCommander{})
-	if err != nil {
-		return 1, fmt.Errorf("environment checks failed: %w", err)
+// Options configures a bootstrap run.
+type Options struct {
+	// ExpandEnv writes interpolated values to .env instead of ${VAR} references.
+	ExpandEnv bool
+	// ExpandFromOS resolves references from the process environment when no
+	// entry defines them.
+	ExpandFromOS bool
+	// Profile selects the generated file: .env when empty, .env.<profile>
+	// otherwise, with .env.example.<profile> applied over .env.example.
+	Profile string
+	// Backup keeps a timestamped copy of an existing .env before it is
+	// rewritten.
+	Backup bool
+	// KeyFile holds the key used to decrypt a committed .env.enc when
+	// $ILAUNCH_ENV_KEY is not set.
+	KeyFile string
+	// FrozenLockfile installs dependencies without updating the lockfile.
+	FrozenLockfile bool
+	// Corepack provisions the package manager version pinned in
+	// package.json when the installed one differs.
+	Corepack bool
+	// UseVersionManager switches to, or installs, a node that satisfies the
+	// project through nvm, fnm, volta, asdf or mise when the installed one
+	// does not.
+	UseVersionManager bool
+}
+
+func RunInteractive(ctx context.Context, opts Options) (int, error) {
+	check, err := checkEnvironment(ctx, nil)
+	model := NewModel(check, opts)
+	var nodeErr *system.NodeVersionError
+	if errors.As(err, &nodeErr) {
+		choices, chooseErr := toolchainChoices(check.NodeConstraints)
+		if chooseErr != nil {
+			return 1, fmt.Errorf("environment checks failed: %w (%v)", err, chooseErr)
+		}
+		model.offerToolchains(err, choices)
+	} else {
+		provision, err := pendingProvision(check, err)
+		if err != nil {
+			return 1, fmt.Errorf("environment checks failed: %w", err)
+		}
+		if provision != nil {
+			model.provision = provision
+			model.screen = ScreenProvision
+		}
+	}
+	if plan, err := planEnv(opts); err == nil {
+		model.runner.Secrets = plan.secrets()
 	}
-	model := NewModel(check)
 	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion(), tea.WithContext(ctx))
 	final, err := program.Run()
 	if err != nil {
@@ -33,24 +82,72 @@ func RunInteractive(ctx context.Context) (int, error) {
 	return m.exitCodeOrDefault(), nil
 }
 
-func RunNonInteractive(ctx context.Context) (int, error) {
-	check, err := system.CheckEnvironment(ctx, system.ExecCommander{})
+func RunNonInteractive(ctx context.Context, opts Options) (int, error) {
+	check, err := checkEnvironment(ctx, nil)
+	var nodeErr *system.NodeVersionError
+	if errors.As(err, &nodeErr) {
+		if !opts.UseVersionManager {
+			return 1, fmt.Errorf("environment checks failed: %w (rerun with --use-version-manager to switch node)", err)
+		}
+		tc, code, switchErr := switchNode(ctx, check.NodeConstraints)
+		if switchErr != nil {
+			return code, switchErr
+		}
+		check, err = checkEnvironment(ctx, tc)
+	}
+	provision, err := pendingProvision(check, err)
 	if err != nil {
 		return 1, fmt.Errorf("environment checks failed: %w", err)
 	}
-
-	if _, err = os.Stat(".env"); os.IsNotExist(err) {
-		if err = createEnvWithDefaults(); err != nil {
-			return 1, fmt.Errorf("create env: %w", err)
+	switch {
+	case provision != nil && opts.Corepack:
+		if code, err := provisionPackageManager(ctx, check, *provision); err != nil {
+			return code, err
+		}
+		if check, err = checkEnvironment(ctx, check.Toolchain); err != nil {
+			return 1, fmt.Errorf("environment checks failed after provisioning: %w", err)
+		}
+		if check.Provision != nil {
+			return 1, fmt.Errorf("still not provisioned: %s", check.Provision)
 		}
-		fmt.Println("created .env from .env.example defaults")
+	case provision != nil && check.PackageManager == nil:
+		return 1, fmt.Errorf("environment checks failed: %s (rerun with --corepack to provision it)", provision)
+	}
+	for _, w := range check.Warnings {
+		fmt.Println("warning: " + w)
+	}
+
+	note, err := decryptEnv(opts)
+	if err != nil {
+		return 1, err
+	}
+	if note != "" {
+		fmt.Println(note)
+	}
+	plan, err := createEnvWithDefaults(opts)
+	if err != nil {
+		return 1, fmt.Errorf("create env: %w", err)
+	}
+	for _, line := range plan.summary() {
+		fmt.Println(line)
 	}
 
-	r := runner.Runner{}
-	if code, err := streamProcess(ctx, r, check.PackageMgr, "install"); err != nil {
+	r := runner.Runner{Secrets: plan.secrets(), Env: toolchainEnv(check)}
+	install, err := installCommand(check, opts)
+	if err != nil {
+		return 1, err
+	}
+	if code, err := streamProcess(ctx, r, install[0], install[1:]...); err != nil {
 		return code, err
 	}
 	if _, err = os.Stat(".git"); os.IsNotExist(err) {
+		note, err := ignoreSecrets(opts)
+		if err != nil {
+			return 1, err
+		}
+		if note != "" {
+			fmt.Println(note)
+		}
 		if code, err := streamProcess(ctx, r, "git", "init"); err != nil {
 			return code, err
 		}
@@ -64,6 +161,101 @@ func RunNonInteractive(ctx context.Context) (int, error) {
 	return 0, nil
 }
 
+// installCommand returns the dependency install command of the detected
+// package manager.
+func installCommand(check system.CheckResult, opts Options) ([]string, error) {
+	pm := check.PackageManager
+	if pm == nil {
+		return nil, fmt.Errorf("%s is not installed; provision it first", check.PackageMgr)
+	}
+	return append([]string{pm.Name()}, pm.InstallArgs(opts.FrozenLockfile)...), nil
+}
+
+// pendingProvision separates a pinned package manager that corepack can
+// provide from other check failures. It returns nil when the installed
+// version is the pinned one.
+func pendingProvision(check system.CheckResult, err error) (*system.Provision, error) {
+	var perr *system.ProvisionError
+	if errors.As(err, &perr) {
+		return &perr.Provision, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return check.Provision, nil
+}
+
+// provisionPackageManager installs and activates the pinned package manager
+// with the corepack of the selected node, streaming its output.
+func provisionPackageManager(ctx context.Context, check system.CheckResult, p system.Provision) (int, error) {
+	env := toolchainEnv(check)
+	cmds, err := system.ProvisionCommands(system.ExecCommander{Env: env}, p)
+	if err != nil {
+		return 1, err
+	}
+	for _, c := range cmds {
+		if code, err := streamProcess(ctx, runner.Runner{Env: env}, c[0], c[1:]...); err != nil {
+			return code, fmt.Errorf("provision %s@%s: %w", p.Name, p.Version, err)
+		}
+	}
+	return 0, nil
+}
+
+// checkEnvironment runs the environment checks with the node of tc first on
+// PATH, or with the node already on PATH when tc is nil.
+func checkEnvironment(ctx context.Context, tc *system.Toolchain) (system.CheckResult, error) {
+	commander := system.ExecCommander{}
+	if tc != nil {
+		commander.Env = tc.Env(os.Environ())
+	}
+	res, err := system.CheckEnvironment(ctx, commander, ".")
+	res.Toolchain = tc
+	return res, err
+}
+
+// toolchainEnv is the environment for processes of a bootstrap: nil to
+// inherit it, or one with the selected node first on PATH.
+func toolchainEnv(check system.CheckResult) []string {
+	if check.Toolchain == nil {
+		return nil
+	}
+	return check.Toolchain.Env(os.Environ())
+}
+
+// switchNode selects the newest installed node that satisfies constraints,
+// installing one with the first available version manager if there is none.
+func switchNode(ctx context.Context, constraints []system.NodeConstraint) (*system.Toolchain, int, error) {
+	managers := system.DetectVersionManagers(system.ExecCommander{})
+	if len(managers) == 0 {
+		return nil, 1, errNoVersionManager
+	}
+	matches, err := system.SatisfyingToolchains(managers, constraints)
+	if err != nil {
+		return nil, 1, err
+	}
+	if len(matches) == 0 {
+		target, ok := system.InstallTarget(constraints)
+		if !ok {
+			return nil, 1, fmt.Errorf("no node release satisfies the project requirements")
+		}
+		cmd := managers[0].InstallCommand(target)
+		if code, err := streamProcess(ctx, runner.Runner{}, cmd[0], cmd[1:]...); err != nil {
+			return nil, code, fmt.Errorf("install node %s with %s: %w", target, managers[0].Name, err)
+		}
+		if matches, err = system.SatisfyingToolchains(managers, constraints); err != nil {
+			return nil, 1, err
+		}
+		if len(matches) == 0 {
+			return nil, 1, fmt.Errorf("%s installed node %s, but it does not satisfy the project requirements", managers[0].Name, target)
+		}
+	}
+	tc := matches[0]
+	fmt.Printf("using %s from %s\n", tc, tc.BinDir)
+	return &tc, 0, nil
+}
+
+var errNoVersionManager = errors.New("no node version manager found (looked for nvm, fnm, volta, asdf and mise)")
+
 func streamProcess(ctx context.Context, r runner.Runner, name string, args ...string) (int, error) {
 	fmt.Printf("$ %s %v\n", name, args)
 	for ev := range r.Run(ctx, name, args...) {
@@ -81,29 +273,231 @@ func streamProcess(ctx context.Context, r runner.Runner, name string, args ...st
 	return 0, nil
 }
 
-func createEnvWithDefaults() error {
-	file, err := os.Open(".env.example")
-	if err != nil {
-		return fmt.Errorf("open .env.example: %w", err)
+// envPlan is the work needed to bring a profile's .env file in line with
+// .env.example and its overlay: either a fresh file, or a merge that keeps
+// existing values and only asks for keys the template introduced since.
+type envPlan struct {
+	doc      *env.Document
+	current  *env.Document
+	target   string
+	values   map[string]string
+	fields   []env.Entry
+	existing bool
+	obsolete []string
+	expand   bool
+	lookup   func(string) (string, bool)
+	backup   bool
+	backedUp string
+	warnings []string
+}
+
+func planEnv(opts Options) (envPlan, error) {
+	if err := env.ValidateProfile(opts.Profile); err != nil {
+		return envPlan{}, err
 	}
-	defer file.Close()
-	entries, err := env.ParseExample(file)
+	doc, err := env.ReadDocument(env.ExampleFile)
 	if err != nil {
-		return fmt.Errorf("parse .env.example: %w", err)
+		return envPlan{}, err
 	}
-	values := make(map[string]string, len(entries))
-	for _, e := range entries {
-		if e.Default == "" {
-			return fmt.Errorf("empty default value for key %s", e.Key)
+	if opts.Profile != "" {
+		overlay, err := env.ReadDocument(env.OverlayFile(opts.Profile))
+		switch {
+		case err == nil:
+			doc = env.Overlay(doc, overlay)
+		case !errors.Is(err, fs.ErrNotExist):
+			return envPlan{}, err
 		}
-		values[e.Key] = e.Default
 	}
-	if err := env.WriteFile(".env", values); err != nil {
-		return fmt.Errorf("write .env: %w", err)
+	plan := envPlan{
+		doc:    doc,
+		target: env.ProfileFile(opts.Profile),
+		values: map[string]string{},
+		fields: doc.Entries(),
+		expand: opts.ExpandEnv,
+		backup: opts.Backup,
+	}
+	if opts.ExpandFromOS {
+		plan.lookup = os.LookupEnv
+	}
+	current, err := env.ReadDocument(plan.target)
+	switch {
+	case err == nil:
+		res := env.Merge(doc, current.Entries())
+		plan.existing = true
+		plan.current = current
+		plan.values = res.Values
+		plan.fields = res.Added
+		plan.obsolete = res.Obsolete
+	case !errors.Is(err, fs.ErrNotExist):
+		return envPlan{}, err
+	}
+	return plan, nil
+}
+
+func (p *envPlan) write() error {
+	if p.existing && len(p.fields) == 0 {
+		return nil
+	}
+	if p.backup && p.existing {
+		path, err := env.BackupFile(p.target, time.Now())
+		if err != nil {
+			return err
+		}
+		p.backedUp = path
+	}
+	values := p.values
+	if p.expand {
+		expanded, err := p.expandFields(values)
+		if err != nil {
+			return err
+		}
+		values = maps.Clone(values)
+		maps.Copy(values, expanded)
+	}
+	if err := env.WriteDocument(p.target, p.document(), values); err != nil {
+		return fmt.Errorf("write %s: %w", p.target, err)
 	}
 	return nil
 }
 
+// document returns the document write renders: the template, or the
+// existing file extended with the new fields.
+func (p *envPlan) document() *env.Document {
+	if p.existing {
+		return env.Extend(p.current, p.doc, p.fields)
+	}
+	return p.doc
+}
+
+// expandFields resolves the ${VAR} references of the fields being written,
+// leaving the values already in an existing file untouched.
+func (p *envPlan) expandFields(values map[string]string) (map[string]string, error) {
+	keys := make([]string, 0, len(p.fields))
+	for _, e := range p.fields {
+		keys = append(keys, e.Key)
+	}
+	return p.document().ExpandKeys(values, p.lookup, keys)
+}
+
+func (p envPlan) summary() []string {
+	lines := make([]string, 0, 2)
+	switch {
+	case !p.existing:
+		lines = append(lines, fmt.Sprintf("created %s from %s", p.target, env.ExampleFile))
+	case len(p.fields) == 0:
+		lines = append(lines, fmt.Sprintf("%s is up to date with %s", p.target, env.ExampleFile))
+	default:
+		keys := make([]string, 0, len(p.fields))
+		for _, e := range p.fields {
+			keys = append(keys, e.Key)
+		}
+		lines = append(lines, fmt.Sprintf("added %d new keys to %s: %s", len(keys), p.target, strings.Join(keys, ", ")))
+	}
+	if p.backedUp != "" {
+		lines = append(lines, fmt.Sprintf("previous %s saved to %s", p.target, p.backedUp))
+	}
+	lines = append(lines, p.warnings...)
+	if len(p.obsolete) > 0 {
+		lines = append(lines, fmt.Sprintf("obsolete keys in %s (not in %s): %s", p.target, env.ExampleFile, strings.Join(p.obsolete, ", ")))
+	}
+	return lines
+}
+
+// secrets returns the values of every key that is annotated or named as a
+// secret, so the runner can redact them from process output.
+func (p envPlan) secrets() []string {
+	schema := make(map[string]env.Entry)
+	for _, e := range p.doc.Entries() {
+		schema[e.Key] = e
+	}
+	secrets := make([]string, 0)
+	for k, v := range p.values {
+		e, ok := schema[k]
+		if !ok {
+			e = env.Entry{Key: k}
+		}
+		if v != "" && e.IsSecret() {
+			secrets = append(secrets, v)
+		}
+	}
+	return secrets
+}
+
+// ignoreSecrets adds the plaintext env file, its backups and the key file to
+// .gitignore before the initial commit stages the whole project.
+func ignoreSecrets(opts Options) (string, error) {
+	added, err := env.EnsureIgnored(env.GitignoreFile, env.SecretPatterns(env.ProfileFile(opts.Profile), opts.KeyFile)...)
+	if err != nil || len(added) == 0 {
+		return "", err
+	}
+	return fmt.Sprintf("added %s to %s", strings.Join(added, ", "), env.GitignoreFile), nil
+}
+
+// decryptEnv materializes the profile's env file from its committed .enc
+// counterpart when the plain file does not exist yet. A missing key is not
+// an error, so contributors without access can still bootstrap from the
+// example defaults.
+func decryptEnv(opts Options) (string, error) {
+	target := env.ProfileFile(opts.Profile)
+	source := env.EncryptedFile(target)
+	sealed, err := os.ReadFile(source)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", source, err)
+	}
+	if _, err = os.Stat(target); err == nil {
+		return "", nil
+	}
+	key, err := env.LoadKey(opts.KeyFile)
+	if errors.Is(err, env.ErrNoKey) {
+		return fmt.Sprintf("skipping %s: %v", source, err), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	plain, err := env.Decrypt(sealed, key)
+	if err != nil {
+		return "", fmt.Errorf("decrypt %s: %w", source, err)
+	}
+	if err = env.WriteAtomic(target, plain); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("decrypted %s to %s", source, target), nil
+}
+
+// createEnvWithDefaults creates or merges .env, filling every missing key
+// with its default from .env.example or a value from its @generate spec.
+func createEnvWithDefaults(opts Options) (envPlan, error) {
+	plan, err := planEnv(opts)
+	if err != nil {
+		return envPlan{}, err
+	}
+	for _, e := range plan.fields {
+		value, err := e.Value()
+		if err != nil {
+			return envPlan{}, err
+		}
+		plan.values[e.Key] = e.Normalize(value)
+	}
+	// A default such as ${PORT} is checked as the value it resolves to,
+	// whether or not .env is written expanded.
+	expanded, err := plan.expandFields(plan.values)
+	if err != nil {
+		return envPlan{}, err
+	}
+	for _, e := range plan.fields {
+		if err := e.Validate(expanded[e.Key]); err != nil {
+			return envPlan{}, fmt.Errorf("invalid default value: %w", err)
+		}
+	}
+	if err := plan.write(); err != nil {
+		return envPlan{}, err
+	}
+	return plan, nil
+}
+
 func (m Model) exitCodeOrDefault() int {
 	if m.exitCode != 0 {
 		return m.exitCode
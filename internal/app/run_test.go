@@ -0,0 +1,84 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateEnvWithDefaults(t *testing.T) {
+	const example = "# Server\nPORT=3000\nAPI_URL=http://localhost:${PORT}\n"
+	tests := []struct {
+		name     string
+		existing string // "" when there is no .env yet
+		want     string
+		summary  []string
+		backup   bool
+	}{
+		{
+			name:    "create",
+			want:    example,
+			summary: []string{"created .env from .env.example"},
+		},
+		{
+			name:     "merge new keys",
+			existing: "# mine\nPORT=4000\nLEGACY=1\n",
+			want:     "# mine\nPORT=4000\nLEGACY=1\nAPI_URL=http://localhost:${PORT}\n",
+			summary: []string{
+				"added 1 new keys to .env: API_URL",
+				"previous .env saved to .env.bak.",
+				"obsolete keys in .env (not in .env.example): LEGACY",
+			},
+			backup: true,
+		},
+		{
+			name:     "up to date",
+			existing: "PORT=4000\nAPI_URL=https://api.example.com\n",
+			want:     "PORT=4000\nAPI_URL=https://api.example.com\n",
+			summary:  []string{".env is up to date with .env.example"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			if err := os.WriteFile(".env.example", []byte(example), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.existing != "" {
+				if err := os.WriteFile(".env", []byte(tt.existing), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			plan, err := createEnvWithDefaults(Options{Backup: true})
+			if err != nil {
+				t.Fatalf("createEnvWithDefaults() error = %v", err)
+			}
+			data, err := os.ReadFile(".env")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Fatalf(".env =\n%s\nwant\n%s", data, tt.want)
+			}
+			summary := plan.summary()
+			if len(summary) != len(tt.summary) {
+				t.Fatalf("summary() = %q, want %q", summary, tt.summary)
+			}
+			for i, prefix := range tt.summary {
+				if !strings.HasPrefix(summary[i], prefix) {
+					t.Fatalf("summary()[%d] = %q, want prefix %q", i, summary[i], prefix)
+				}
+			}
+			backups, _ := filepath.Glob(".env.bak.*")
+			if got := len(backups) == 1; got != tt.backup {
+				t.Fatalf("backups = %v, want backup %v", backups, tt.backup)
+			}
+			if tt.backup {
+				if data, _ := os.ReadFile(backups[0]); string(data) != tt.existing {
+					t.Fatalf("backup = %q, want %q", data, tt.existing)
+				}
+			}
+		})
+	}
+}
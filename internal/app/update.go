@@ -1,11 +1,12 @@
 package app
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"strings"
 
 	"ilaunch/internal/env"
+	"ilaunch/internal/system"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -31,6 +32,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ErrorMsg:
 		m.setError(typed.Err)
 		return m, nil
+	case CheckMsg:
+		return m.handleCheckMsg(typed)
 	case ProgressMsg:
 		if typed.Value > m.progress {
 			m.progress = typed.Value
@@ -56,7 +59,18 @@ func (m Model) handleKey(k tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.setError(fmt.Errorf("operation canceled"))
 			return m, nil
 		}
-		if m.screen == ScreenEnvForm || m.screen == ScreenLogs {
+		if m.screen == ScreenToolchain || m.screen == ScreenProvision {
+			if m.checkResult.PackageManager == nil {
+				return m, tea.Quit
+			}
+			m.screen = ScreenMenu
+			return m, nil
+		}
+		if m.screen == ScreenEnvReview {
+			m.screen = ScreenEnvForm
+			return m, nil
+		}
+		if m.screen == ScreenProfile || m.screen == ScreenEnvForm || m.screen == ScreenLogs {
 			m.screen = ScreenMenu
 			return m, nil
 		}
@@ -79,8 +93,46 @@ func (m Model) handleKey(k tea.KeyMsg) (tea.Model, tea.Cmd) {
 		case "enter":
 			return m.handleMenuAction()
 		}
+	case ScreenProfile:
+		switch k.String() {
+		case "up":
+			if m.profileIdx > 0 {
+				m.profileIdx--
+			}
+		case "down":
+			if m.profileIdx < len(m.profiles)-1 {
+				m.profileIdx++
+			}
+		case "enter":
+			m.opts.Profile = m.profiles[m.profileIdx].name
+			cmd := m.beginCreateEnv()
+			return m, cmd
+		}
+	case ScreenToolchain:
+		switch k.String() {
+		case "up":
+			if m.toolchainIdx > 0 {
+				m.toolchainIdx--
+			}
+		case "down":
+			if m.toolchainIdx < len(m.toolchains)-1 {
+				m.toolchainIdx++
+			}
+		case "enter":
+			cmd := m.chooseToolchain()
+			return m, cmd
+		}
+	case ScreenProvision:
+		if k.String() == "enter" {
+			cmd := m.beginProvision()
+			return m, cmd
+		}
 	case ScreenEnvForm:
 		return m.handleEnvFormInput(k)
+	case ScreenEnvReview:
+		if k.String() == "enter" {
+			m.submitEnvForm()
+		}
 	case ScreenLogs:
 		switch k.String() {
 		case "up":
@@ -103,14 +155,23 @@ func (m Model) handleKey(k tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleMenuAction() (tea.Model, tea.Cmd) {
 	switch m.menuIndex {
 	case 0:
-		return m, m.beginCreateEnv()
+		cmd := m.beginPickProfile()
+		return m, cmd
 	case 1:
-		cmd := m.startProcess(m.checkResult.PackageMgr, "install")
+		install, err := installCommand(m.checkResult, m.opts)
+		if err != nil {
+			m.setError(err)
+			return m, nil
+		}
+		m.enqueue(install)
+		cmd := m.startNextQueued()
 		return m, cmd
 	case 2:
-		return m, m.startGitInit()
+		cmd := m.startGitInit()
+		return m, cmd
 	case 3:
-		return m, m.runAll()
+		cmd := m.runAll()
+		return m, cmd
 	case 4:
 		return m, tea.Quit
 	default:
@@ -118,25 +179,60 @@ func (m Model) handleMenuAction() (tea.Model, tea.Cmd) {
 	}
 }
 
-func (m Model) startGitInit() tea.Cmd {
+func (m *Model) startGitInit() tea.Cmd {
 	if _, err := os.Stat(".git"); err == nil {
 		m.addLog("git already initialized")
 		return nil
 	}
+	if !m.ignoreSecrets() {
+		return nil
+	}
 	m.enqueue([]string{"git", "init"}, []string{"git", "add", "."}, []string{"git", "commit", "-m", "Initial commit"})
 	return m.startNextQueued()
 }
 
-func (m Model) runAll() tea.Cmd {
-	if _, err := os.Stat(".env"); os.IsNotExist(err) {
-		if createErr := createEnvWithDefaults(); createErr != nil {
-			m.setError(fmt.Errorf("create env defaults: %w", createErr))
-			return nil
-		}
-		m.addLog(".env file created from defaults")
+// ignoreSecrets keeps secrets out of the initial commit and reports whether
+// it may go ahead.
+func (m *Model) ignoreSecrets() bool {
+	note, err := ignoreSecrets(m.opts)
+	if err != nil {
+		m.setError(err)
+		return false
+	}
+	if note != "" {
+		m.addLog(note)
+	}
+	return true
+}
+
+func (m *Model) runAll() tea.Cmd {
+	note, err := decryptEnv(m.opts)
+	if err != nil {
+		m.setError(err)
+		return nil
+	}
+	if note != "" {
+		m.addLog(note)
+	}
+	plan, err := createEnvWithDefaults(m.opts)
+	if err != nil {
+		m.setError(fmt.Errorf("create env defaults: %w", err))
+		return nil
+	}
+	m.runner.Secrets = plan.secrets()
+	for _, line := range plan.summary() {
+		m.addLog(line)
+	}
+	install, err := installCommand(m.checkResult, m.opts)
+	if err != nil {
+		m.setError(err)
+		return nil
 	}
-	m.enqueue([]string{m.checkResult.PackageMgr, "install"})
+	m.enqueue(install)
 	if _, err := os.Stat(".git"); os.IsNotExist(err) {
+		if !m.ignoreSecrets() {
+			return nil
+		}
 		m.enqueue([]string{"git", "init"}, []string{"git", "add", "."}, []string{"git", "commit", "-m", "Initial commit"})
 	}
 	return m.startNextQueued()
@@ -160,7 +256,13 @@ func (m Model) handleProcessMsg(msg ProcessMsg) (tea.Model, tea.Cmd) {
 		}
 		m.addLog("process completed successfully")
 		if len(m.pending) > 0 {
-			return m, m.startNextQueued()
+			cmd := m.startNextQueued()
+			return m, cmd
+		}
+		if m.recheck {
+			m.recheck = false
+			m.addLog("re-running environment checks")
+			return m, recheckEnvironment(m.ctx, m.toolchain)
 		}
 		return m, nil
 	case 2:
@@ -171,36 +273,68 @@ func (m Model) handleProcessMsg(msg ProcessMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
+func (m Model) handleCheckMsg(msg CheckMsg) (tea.Model, tea.Cmd) {
+	var nodeErr *system.NodeVersionError
+	if errors.As(msg.Err, &nodeErr) {
+		choices, err := toolchainChoices(msg.Result.NodeConstraints)
+		if err != nil {
+			m.setError(fmt.Errorf("environment checks failed: %w (%v)", msg.Err, err))
+			return m, nil
+		}
+		m.offerToolchains(msg.Err, choices)
+		return m, nil
+	}
+	provision, err := pendingProvision(msg.Result, msg.Err)
+	if err != nil {
+		m.setError(fmt.Errorf("environment checks failed: %w", err))
+		return m, nil
+	}
+	m.checkResult = msg.Result
+	if provision != nil {
+		if m.provisioning {
+			m.setError(fmt.Errorf("still not provisioned: %s", provision))
+			return m, nil
+		}
+		m.provision = provision
+		m.screen = ScreenProvision
+		return m, nil
+	}
+	m.provision = nil
+	m.provisioning = false
+	m.addLog(fmt.Sprintf("using node %s and %s %s", m.checkResult.NodeVersion, m.checkResult.PackageMgr, m.checkResult.PackageMgrVersion))
+	return m, nil
+}
+
 func (m Model) handleEnvFormInput(k tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if len(m.envEntries) == 0 {
-		m.setError(fmt.Errorf(".env.example has no entries"))
+		m.setError(fmt.Errorf("%s has no entries", env.ExampleFile))
 		return m, nil
 	}
-	switch k.Type {
-	case tea.KeyBackspace:
-		if len(m.fieldInput) > 0 {
-			m.fieldInput = m.fieldInput[:len(m.fieldInput)-1]
-		}
-	case tea.KeyRunes:
-		m.fieldInput += string(k.Runes)
-	case tea.KeyEnter:
-		current := m.envEntries[m.fieldIndex]
-		if strings.TrimSpace(m.fieldInput) == "" {
-			m.setError(fmt.Errorf("value for %s cannot be empty", current.Key))
+	i := m.fieldIndex
+	switch k.String() {
+	case "tab", "down":
+		m.checkField(i)
+		m.focusField(i + 1)
+	case "shift+tab", "up":
+		m.checkField(i)
+		m.focusField(i - 1)
+	case "ctrl+r":
+		if err := m.resetField(i); err != nil {
+			m.setError(err)
+		}
+	case "enter":
+		if !m.checkField(i) {
 			return m, nil
 		}
-		m.envValues[current.Key] = strings.TrimSpace(m.fieldInput)
-		m.fieldIndex++
-		if m.fieldIndex >= len(m.envEntries) {
-			if err := env.WriteFile(".env", m.envValues); err != nil {
-				m.setError(fmt.Errorf("write .env: %w", err))
-				return m, nil
-			}
-			m.addLog(".env file created")
-			m.screen = ScreenMenu
+		if i < len(m.envEntries)-1 {
+			m.focusField(i + 1)
 			return m, nil
 		}
-		m.fieldInput = m.envEntries[m.fieldIndex].Default
+		m.beginReview()
+	default:
+		m.fieldInputs[i].Update(k)
+		m.fieldErrs[i] = ""
+		m.fieldWarns[i] = nil
 	}
 	return m, nil
 }
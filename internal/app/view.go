@@ -2,8 +2,12 @@ package app
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
+	"ilaunch/internal/env"
+	"ilaunch/internal/ui/components"
+
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -13,14 +17,23 @@ var (
 	boxStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(1, 2)
 	errStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
 	mutedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	warnStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
 )
 
 func (m Model) View() string {
 	switch m.screen {
 	case ScreenMenu:
 		return m.viewMenu()
+	case ScreenToolchain:
+		return m.viewToolchainPicker()
+	case ScreenProvision:
+		return m.viewProvision()
+	case ScreenProfile:
+		return m.viewProfilePicker()
 	case ScreenEnvForm:
 		return m.viewEnvForm()
+	case ScreenEnvReview:
+		return m.viewEnvReview()
 	case ScreenLogs:
 		return m.viewLogs()
 	case ScreenError:
@@ -31,7 +44,25 @@ func (m Model) View() string {
 }
 
 func (m Model) viewMenu() string {
-	rows := []string{titleStyle.Render("iLaunch — Project Bootstrap TUI"), mutedStyle.Render(fmt.Sprintf("Node %s | %s", m.checkResult.NodeVersion, m.checkResult.PackageMgr)), ""}
+	node := "Node " + m.checkResult.NodeVersion
+	if m.checkResult.Toolchain != nil {
+		node += " via " + m.checkResult.Toolchain.Manager
+	}
+	for _, c := range m.checkResult.NodeConstraints {
+		node += fmt.Sprintf(" (%s)", c)
+	}
+	pm := fmt.Sprintf("%s %s", m.checkResult.PackageMgr, m.checkResult.PackageMgrVersion)
+	if m.checkResult.PackageMgrSource != "" {
+		pm += fmt.Sprintf(" (from %s)", m.checkResult.PackageMgrSource)
+	}
+	rows := []string{titleStyle.Render("iLaunch — Project Bootstrap TUI"), mutedStyle.Render(node + " | " + pm)}
+	if len(m.checkResult.Workspaces) > 0 {
+		rows = append(rows, mutedStyle.Render("Workspaces: "+strings.Join(m.checkResult.Workspaces, ", ")))
+	}
+	for _, w := range m.checkResult.Warnings {
+		rows = append(rows, warnStyle.Render("! "+w))
+	}
+	rows = append(rows, "")
 	for i, item := range menuItems {
 		prefix := "  "
 		style := lipgloss.NewStyle()
@@ -45,19 +76,228 @@ func (m Model) viewMenu() string {
 	return boxStyle.Width(m.width - 4).Render(strings.Join(rows, "\n"))
 }
 
+func (m Model) viewToolchainPicker() string {
+	rows := []string{titleStyle.Render("Node version mismatch"), "", errStyle.Render(m.nodeErr.Error()), ""}
+	for i, c := range m.toolchains {
+		detail := ""
+		if c.toolchain != nil {
+			detail = " → " + c.toolchain.BinDir
+		}
+		prefix := "  "
+		style := lipgloss.NewStyle()
+		if m.toolchainIdx == i {
+			prefix = "➜ "
+			style = focusStyle
+		}
+		rows = append(rows, style.Render(prefix+c.label)+mutedStyle.Render(detail))
+	}
+	rows = append(rows, "", mutedStyle.Render("↑/↓ navigate • Enter select • Esc exit"))
+	return boxStyle.Width(m.width - 4).Render(strings.Join(rows, "\n"))
+}
+
+func (m Model) viewProvision() string {
+	p := *m.provision
+	rows := []string{
+		titleStyle.Render("Package manager version mismatch"),
+		"",
+		warnStyle.Render(p.String()),
+		"",
+		fmt.Sprintf("Provision %s@%s with corepack?", p.Name, p.Version),
+		mutedStyle.Render(fmt.Sprintf("Runs corepack enable %s and corepack prepare %s@%s --activate", p.Name, p.Name, p.Version)),
+		"",
+	}
+	if p.Installed == "" {
+		rows = append(rows, mutedStyle.Render("Enter provision • Esc exit"))
+	} else {
+		rows = append(rows, mutedStyle.Render(fmt.Sprintf("Enter provision • Esc continue with %s %s", p.Name, p.Installed)))
+	}
+	return boxStyle.Width(m.width - 4).Render(strings.Join(rows, "\n"))
+}
+
+func (m Model) viewProfilePicker() string {
+	rows := []string{titleStyle.Render("Select environment profile"), ""}
+	for i, p := range m.profiles {
+		name := "default"
+		if p.name != "" {
+			name = p.name
+		}
+		detail := " → " + env.ProfileFile(p.name)
+		if p.overlay {
+			detail += " (with " + env.OverlayFile(p.name) + ")"
+		}
+		prefix := "  "
+		style := lipgloss.NewStyle()
+		if m.profileIdx == i {
+			prefix = "➜ "
+			style = focusStyle
+		}
+		rows = append(rows, style.Render(prefix+name)+mutedStyle.Render(detail))
+	}
+	rows = append(rows, "", mutedStyle.Render("↑/↓ navigate • Enter select • Esc back"))
+	return boxStyle.Width(m.width - 4).Render(strings.Join(rows, "\n"))
+}
+
 func (m Model) viewEnvForm() string {
 	entry := m.envEntries[m.fieldIndex]
 	rows := []string{
-		titleStyle.Render("Create .env file"),
+		titleStyle.Render("Create " + m.envPlan.target),
 		mutedStyle.Render(fmt.Sprintf("Field %d/%d", m.fieldIndex+1, len(m.envEntries))),
 		"",
-		focusStyle.Render(entry.Key + "=" + m.fieldInput),
-		"",
-		mutedStyle.Render("Type value and press Enter • Esc back"),
 	}
+	width := keyWidth(m.envEntries)
+	start, end := m.visibleFields()
+	if start > 0 {
+		rows = append(rows, mutedStyle.Render(fmt.Sprintf("  ↑ %d more", start)))
+	}
+	for i := start; i < end; i++ {
+		label := fmt.Sprintf("%-*s = ", width, m.envEntries[i].Key)
+		if i == m.fieldIndex {
+			rows = append(rows, focusStyle.Render("➜ "+label)+m.fieldInputs[i].ViewFocused())
+			continue
+		}
+		row := "  " + label + m.fieldDisplay(i)
+		switch {
+		case m.fieldErrs[i] != "":
+			row += errStyle.Render(" ✗")
+		case len(m.fieldWarns[i]) > 0:
+			row += warnStyle.Render(" !")
+		}
+		rows = append(rows, row)
+	}
+	if end < len(m.envEntries) {
+		rows = append(rows, mutedStyle.Render(fmt.Sprintf("  ↓ %d more", len(m.envEntries)-end)))
+	}
+	rows = append(rows, "")
+	if err := m.fieldErrs[m.fieldIndex]; err != "" {
+		rows = append(rows, errStyle.Render("✗ "+err))
+	}
+	for _, w := range m.fieldWarns[m.fieldIndex] {
+		rows = append(rows, warnStyle.Render("! "+w))
+	}
+	if hint := schemaHint(entry); hint != "" {
+		rows = append(rows, mutedStyle.Render(hint))
+	}
+	if preview := m.envPreview(); len(preview) > 0 {
+		rows = append(rows, "")
+		for _, line := range preview {
+			rows = append(rows, mutedStyle.Render(line))
+		}
+	}
+	help := "Tab/↓ next • Shift+Tab/↑ previous • Ctrl+R reset to default • Enter confirm • Esc back"
+	if _, ok := m.fieldInputs[m.fieldIndex].(*components.Select); ok {
+		help = "←/→ choose • " + help
+	}
+	rows = append(rows, "", mutedStyle.Render(help))
+	return boxStyle.Width(m.width - 4).Render(strings.Join(rows, "\n"))
+}
+
+// visibleFields returns the window of form rows that fits the terminal,
+// keeping the focused field roughly centered.
+func (m Model) visibleFields() (int, int) {
+	rows := max(m.height-18, 3)
+	start := max(0, min(m.fieldIndex-rows/2, len(m.envEntries)-rows))
+	end := min(start+rows, len(m.envEntries))
+	return start, end
+}
+
+// fieldDisplay renders an unfocused value; secrets are masked without
+// revealing their length.
+func (m Model) fieldDisplay(index int) string {
+	input := m.fieldInputs[index]
+	if m.envEntries[index].IsSecret() && input.Value() != "" {
+		return components.MaskedValue
+	}
+	return input.View()
+}
+
+func keyWidth(entries []env.Entry) int {
+	width := 0
+	for _, e := range entries {
+		width = max(width, len(e.Key))
+	}
+	return width
+}
+
+// viewEnvReview lists the values as they will be written, after
+// normalization such as yes becoming true.
+func (m Model) viewEnvReview() string {
+	summary := fmt.Sprintf("%d values will be written", len(m.envEntries))
+	if m.envPlan.existing {
+		summary = fmt.Sprintf("%d new keys will be added, existing values are kept", len(m.envEntries))
+	}
+	rows := []string{titleStyle.Render("Review " + m.envPlan.target), mutedStyle.Render(summary), ""}
+	width := keyWidth(m.envEntries)
+	for i, e := range m.envEntries {
+		value := m.fieldValue(i)
+		if e.IsSecret() && value != "" {
+			value = components.MaskedValue
+		}
+		rows = append(rows, fmt.Sprintf("  %-*s = %s", width, e.Key, value))
+		for _, w := range m.fieldWarns[i] {
+			rows = append(rows, warnStyle.Render("    ! "+w))
+		}
+	}
+	if m.envPlan.existing && m.envPlan.backup {
+		rows = append(rows, "", mutedStyle.Render("The current "+m.envPlan.target+" is backed up first"))
+	}
+	rows = append(rows, "", mutedStyle.Render("Enter write • Esc edit"))
 	return boxStyle.Width(m.width - 4).Render(strings.Join(rows, "\n"))
 }
 
+// envPreview resolves the references that involve the field being edited,
+// so changing PORT shows the resulting API_URL=http://localhost:${PORT}.
+func (m Model) envPreview() []string {
+	current := m.envEntries[m.fieldIndex]
+	values := m.formValues()
+	expanded, err := m.envPlan.doc.Expand(values, m.envPlan.lookup)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	lines := make([]string, 0)
+	for _, e := range m.envPlan.doc.Entries() {
+		raw, ok := values[e.Key]
+		if !ok {
+			raw = e.Default
+		}
+		refs := env.References(raw)
+		if e.Literal || len(refs) == 0 {
+			continue
+		}
+		if e.Key != current.Key && !slices.Contains(refs, current.Key) {
+			continue
+		}
+		shown := expanded[e.Key]
+		if e.IsSecret() {
+			shown = components.MaskedValue
+		}
+		lines = append(lines, fmt.Sprintf("%s → %s", e.Key, shown))
+	}
+	return lines
+}
+
+func schemaHint(e env.Entry) string {
+	parts := make([]string, 0, 4)
+	if e.Description != "" {
+		parts = append(parts, e.Description)
+	}
+	if typ := e.EffectiveType(); typ != "" {
+		parts = append(parts, "type: "+typ)
+	}
+	if e.Min != "" || e.Max != "" {
+		parts = append(parts, fmt.Sprintf("range: %s..%s", e.Min, e.Max))
+	}
+	if len(e.Schemes) > 0 {
+		parts = append(parts, "scheme: "+strings.Join(e.Schemes, ", "))
+	}
+	if len(e.Enum) > 0 {
+		parts = append(parts, "one of: "+strings.Join(e.Enum, ", "))
+	}
+	if e.Required {
+		parts = append(parts, "required")
+	}
+	return strings.Join(parts, " • ")
+}
+
 func (m Model) viewLogs() string {
 	rows := []string{titleStyle.Render("Process logs"), progressBar(m.progress, m.width-12), ""}
 	maxRows := m.height - 8
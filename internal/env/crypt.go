@@ -0,0 +1,171 @@
+package env
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Encrypted dotenv files use AES-256-GCM. A whole-file ciphertext starts
+// with fileHeader; otherwise the file is a regular dotenv document whose
+// values carry valuePrefix, so keys and comments stay reviewable in diffs.
+const (
+	KeyEnvVar      = "ILAUNCH_ENV_KEY"
+	DefaultKeyFile = ".env.key"
+	fileHeader     = "ILAUNCH-ENC-V1"
+	valuePrefix    = "enc:v1:"
+	keySize        = 32
+)
+
+// ErrNoKey is returned by LoadKey, wrapped with the key file it looked
+// for, when neither the environment variable nor the key file provides a
+// key.
+var ErrNoKey = errors.New("no encryption key")
+
+// EncryptedFile returns the path of the encrypted counterpart of path.
+func EncryptedFile(path string) string {
+	return path + ".enc"
+}
+
+func GenerateKey() ([]byte, error) {
+	return randomBytes(keySize)
+}
+
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ParseKey decodes a 32-byte key written as base64 or hex.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(s); err == nil && len(key) == keySize {
+			return key, nil
+		}
+	}
+	if key, err := hex.DecodeString(s); err == nil && len(key) == keySize {
+		return key, nil
+	}
+	return nil, fmt.Errorf("encryption key must be %d bytes encoded as base64 or hex", keySize)
+}
+
+// LoadKey reads the key from the KeyEnvVar environment variable, falling
+// back to keyFile.
+func LoadKey(keyFile string) ([]byte, error) {
+	if v, ok := os.LookupEnv(KeyEnvVar); ok && v != "" {
+		key, err := ParseKey(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", KeyEnvVar, err)
+		}
+		return key, nil
+	}
+	data, err := os.ReadFile(keyFile)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: set %s or create %s", ErrNoKey, KeyEnvVar, keyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+	key, err := ParseKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", keyFile, err)
+	}
+	return key, nil
+}
+
+// EncryptFile encrypts a whole dotenv file.
+func EncryptFile(plain, key []byte) ([]byte, error) {
+	sealed, err := seal(key, plain, []byte(fileHeader))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(fileHeader + "\n" + sealed + "\n"), nil
+}
+
+// EncryptValues encrypts every non-empty value of doc and renders the
+// result as a dotenv document. The key name is bound to its ciphertext, so
+// values cannot be swapped between keys.
+func EncryptValues(doc *Document, key []byte) ([]byte, error) {
+	values := make(map[string]string)
+	for _, e := range doc.Entries() {
+		if e.Default == "" {
+			continue
+		}
+		sealed, err := seal(key, []byte(e.Default), []byte(e.Key))
+		if err != nil {
+			return nil, err
+		}
+		values[e.Key] = valuePrefix + sealed
+	}
+	return doc.Render(values), nil
+}
+
+// Decrypt returns the plaintext dotenv content of an encrypted file in
+// either format.
+func Decrypt(data, key []byte) ([]byte, error) {
+	if rest, ok := bytes.CutPrefix(data, []byte(fileHeader+"\n")); ok {
+		return unseal(key, strings.TrimSpace(string(rest)), []byte(fileHeader))
+	}
+	doc, err := parseDocument(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse encrypted file: %w", err)
+	}
+	values := make(map[string]string)
+	for _, e := range doc.Entries() {
+		sealed, ok := strings.CutPrefix(e.Default, valuePrefix)
+		if !ok {
+			continue
+		}
+		plain, err := unseal(key, sealed, []byte(e.Key))
+		if err != nil {
+			return nil, fmt.Errorf("decrypt %s: %w", e.Key, err)
+		}
+		values[e.Key] = string(plain)
+	}
+	return doc.Render(values), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes", keySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(key, plain, aad []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce, err := randomBytes(gcm.NonceSize())
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plain, aad)), nil
+}
+
+func unseal(key []byte, sealed string, aad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return nil, errors.New("malformed ciphertext")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], aad)
+	if err != nil {
+		return nil, errors.New("wrong key or corrupted ciphertext")
+	}
+	return plain, nil
+}
@@ -0,0 +1,80 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const plainEnv = "# Secrets\nJWT_SECRET=\"s3cr3t value\"\nPORT=3000\nEMPTY=\n"
+
+func TestEncryptFileRoundTrip(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := EncryptFile([]byte(plainEnv), key)
+	if err != nil {
+		t.Fatalf("EncryptFile() error = %v", err)
+	}
+	if strings.Contains(string(enc), "s3cr3t") || !strings.HasPrefix(string(enc), fileHeader) {
+		t.Fatalf("unexpected ciphertext %q", enc)
+	}
+	plain, err := Decrypt(enc, key)
+	if err != nil || string(plain) != plainEnv {
+		t.Fatalf("Decrypt() = %q, %v", plain, err)
+	}
+	other, _ := GenerateKey()
+	if _, err = Decrypt(enc, other); err == nil {
+		t.Fatal("expected error with wrong key")
+	}
+}
+
+func TestEncryptValuesRoundTrip(t *testing.T) {
+	key, _ := GenerateKey()
+	doc, err := ParseDocument(strings.NewReader(plainEnv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := EncryptValues(doc, key)
+	if err != nil {
+		t.Fatalf("EncryptValues() error = %v", err)
+	}
+	text := string(enc)
+	if strings.Contains(text, "s3cr3t") || !strings.Contains(text, "# Secrets\nJWT_SECRET=\"enc:v1:") || !strings.Contains(text, "EMPTY=\n") {
+		t.Fatalf("unexpected per-value ciphertext:\n%s", text)
+	}
+	plain, err := Decrypt(enc, key)
+	if err != nil || string(plain) != plainEnv {
+		t.Fatalf("Decrypt() = %q, %v", plain, err)
+	}
+
+	// Moving a ciphertext to another key must fail authentication.
+	lines := strings.Split(text, "\n")
+	jwt := strings.TrimPrefix(lines[1], "JWT_SECRET=")
+	swapped := strings.Replace(text, lines[2], "PORT="+jwt, 1)
+	if _, err = Decrypt([]byte(swapped), key); err == nil || !strings.Contains(err.Error(), "decrypt PORT") {
+		t.Fatalf("expected swapped value to fail, got %v", err)
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	key, _ := GenerateKey()
+	path := filepath.Join(t.TempDir(), DefaultKeyFile)
+	t.Setenv(KeyEnvVar, "")
+	if _, err := LoadKey(path); !errors.Is(err, ErrNoKey) || !strings.Contains(err.Error(), path) {
+		t.Fatalf("expected ErrNoKey naming %s, got %v", path, err)
+	}
+	if err := os.WriteFile(path, []byte(EncodeKey(key)+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadKey(path); err != nil || string(got) != string(key) {
+		t.Fatalf("LoadKey() from file = %v", err)
+	}
+	t.Setenv(KeyEnvVar, "too-short")
+	if _, err := LoadKey(path); err == nil {
+		t.Fatal("expected invalid env key error")
+	}
+}
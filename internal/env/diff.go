@@ -0,0 +1,33 @@
+package env
+
+// Drift lists the differences between a .env and its template.
+type Drift struct {
+	Missing     []string `json:"missing"`
+	Extra       []string `json:"extra"`
+	Placeholder []string `json:"placeholder"`
+}
+
+// Diff compares current against template. A key is a placeholder when it
+// still holds the template's non-empty default value.
+func Diff(template *Document, current []Entry) Drift {
+	res := Merge(template, current)
+	drift := Drift{Missing: make([]string, 0), Extra: res.Obsolete, Placeholder: make([]string, 0)}
+	if drift.Extra == nil {
+		drift.Extra = make([]string, 0)
+	}
+	for _, e := range res.Added {
+		drift.Missing = append(drift.Missing, e.Key)
+	}
+	for _, e := range template.Entries() {
+		if v, ok := res.Values[e.Key]; ok && e.Default != "" && v == e.Default {
+			drift.Placeholder = append(drift.Placeholder, e.Key)
+		}
+	}
+	return drift
+}
+
+// HasDrift reports whether keys are missing or undocumented. Placeholders
+// only count when strict is set, since many defaults are valid as-is.
+func (d Drift) HasDrift(strict bool) bool {
+	return len(d.Missing) > 0 || len(d.Extra) > 0 || (strict && len(d.Placeholder) > 0)
+}
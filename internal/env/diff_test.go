@@ -0,0 +1,41 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	template, err := ParseDocument(strings.NewReader("PORT=3000\nJWT_SECRET=changeme\nEMPTY=\nREDIS_URL=redis://localhost\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	current, err := Parse("PORT=8080\nJWT_SECRET=changeme\nEMPTY=\nUNDOCUMENTED=1\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	drift := Diff(template, current)
+	if strings.Join(drift.Missing, ",") != "REDIS_URL" {
+		t.Fatalf("unexpected missing keys: %v", drift.Missing)
+	}
+	if strings.Join(drift.Extra, ",") != "UNDOCUMENTED" {
+		t.Fatalf("unexpected extra keys: %v", drift.Extra)
+	}
+	if strings.Join(drift.Placeholder, ",") != "JWT_SECRET" {
+		t.Fatalf("unexpected placeholder keys: %v", drift.Placeholder)
+	}
+	if !drift.HasDrift(false) {
+		t.Fatal("expected drift")
+	}
+}
+
+func TestDiffPlaceholdersOnlyStrict(t *testing.T) {
+	template, err := ParseDocument(strings.NewReader("PORT=3000\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	drift := Diff(template, []Entry{{Key: "PORT", Default: "3000"}})
+	if drift.HasDrift(false) || !drift.HasDrift(true) {
+		t.Fatalf("placeholders must only count as drift in strict mode: %+v", drift)
+	}
+}
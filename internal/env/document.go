@@ -0,0 +1,189 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+type NodeKind int
+
+const (
+	NodeEntry NodeKind = iota
+	NodeComment
+	NodeBlank
+)
+
+// Node is a single line-level element of a dotenv document. Raw holds the
+// original source text so untouched nodes are written back verbatim.
+type Node struct {
+	Kind     NodeKind
+	Entry    Entry
+	Raw      string
+	Exported bool
+	Quote    rune
+	Comment  string
+}
+
+// Document is a round-trippable dotenv file: entries together with the
+// comments and blank lines around them, in source order.
+type Document struct {
+	Nodes []Node
+}
+
+func ParseDocument(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read dotenv source: %w", err)
+	}
+	return parseDocument(string(data))
+}
+
+func parseDocument(input string) (*Document, error) {
+	l := newLexer(input)
+	doc := &Document{}
+	var pending []annotation
+	for {
+		start := l.pos
+		l.skipSpaces()
+		if l.eof() {
+			break
+		}
+		var node Node
+		switch l.peek() {
+		case '\n':
+			l.next()
+			node = Node{Kind: NodeBlank}
+			pending = nil
+		case '#':
+			line, col := l.line, l.col
+			if a, ok := parseAnnotation(l.skipLine(), line, col); ok {
+				pending = append(pending, a)
+			}
+			node = Node{Kind: NodeComment}
+		default:
+			var err error
+			if node, err = l.lexEntry(); err != nil {
+				return nil, err
+			}
+			for _, a := range pending {
+				if err = a.apply(&node.Entry); err != nil {
+					return nil, err
+				}
+			}
+			if err = node.Entry.checkSchema(); err != nil {
+				return nil, &ParseError{Line: node.Entry.Line, Column: 1, Msg: fmt.Sprintf("%s: %v", node.Entry.Key, err)}
+			}
+			pending = nil
+		}
+		node.Raw = strings.TrimSuffix(string(l.src[start:l.pos]), "\n")
+		doc.Nodes = append(doc.Nodes, node)
+	}
+	return doc, nil
+}
+
+func (d *Document) Entries() []Entry {
+	entries := make([]Entry, 0, len(d.Nodes))
+	for _, n := range d.Nodes {
+		if n.Kind == NodeEntry {
+			entries = append(entries, n.Entry)
+		}
+	}
+	return entries
+}
+
+// Render writes the document with each entry's value taken from values,
+// falling back to the entry default. Keys in values that the document does
+// not declare are appended at the end in sorted order.
+func (d *Document) Render(values map[string]string) []byte {
+	var b strings.Builder
+	seen := make(map[string]bool, len(d.Nodes))
+	for _, n := range d.Nodes {
+		if n.Kind != NodeEntry {
+			b.WriteString(n.Raw)
+			b.WriteByte('\n')
+			continue
+		}
+		seen[n.Entry.Key] = true
+		value, ok := values[n.Entry.Key]
+		if !ok || value == n.Entry.Default {
+			b.WriteString(n.Raw)
+			b.WriteByte('\n')
+			continue
+		}
+		b.WriteString(renderEntry(n, value))
+		b.WriteByte('\n')
+	}
+
+	extra := make([]string, 0)
+	for k := range values {
+		if !seen[k] {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+	for _, k := range extra {
+		b.WriteString(renderEntry(Node{Entry: Entry{Key: k}}, values[k]))
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+func renderEntry(n Node, value string) string {
+	line := n.Entry.Key + "=" + quoteValue(value, n.Quote)
+	if n.Exported {
+		line = "export " + line
+	}
+	if n.Comment != "" {
+		line += " " + n.Comment
+	}
+	return line
+}
+
+// quoteValue formats value so that it parses back unchanged, keeping the
+// preferred quote style when the value allows it. Values reach it only when
+// they differ from the template default, so a '$' is literal text, such as
+// in a password, and is single-quoted to keep Expand away from it.
+func quoteValue(value string, preferred rune) string {
+	switch {
+	case strings.ContainsRune(value, '$') && !strings.ContainsAny(value, "'\r"):
+		return "'" + value + "'"
+	case preferred == '\'' && !strings.ContainsRune(value, '\''):
+		return "'" + value + "'"
+	case preferred == '`' && !strings.ContainsRune(value, '`'):
+		return "`" + value + "`"
+	case preferred == 0 && !needsQuotes(value):
+		return value
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", `\r`, "$", `\$`)
+	return `"` + r.Replace(value) + `"`
+}
+
+// needsQuotes reports whether value must be quoted to parse back unchanged
+// by any dotenv reader. Shells and docker --env-file split or trim on
+// interior blanks, so those are quoted too.
+func needsQuotes(value string) bool {
+	if value == "" {
+		return false
+	}
+	if strings.TrimSpace(value) != value {
+		return true
+	}
+	return strings.ContainsAny(value, " \t#$\n\r\"'`")
+}
+
+// ReadDocument opens and parses the dotenv file at path.
+func ReadDocument(path string) (*Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	defer file.Close()
+	doc, err := ParseDocument(file)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,102 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleExample = `# Application settings
+NODE_ENV=development
+PORT = 3000 # http port
+
+# Secrets
+export JWT_SECRET='changeme'
+PEM="-----BEGIN-----
+abc
+-----END-----"
+`
+
+func TestDocumentRoundTrip(t *testing.T) {
+	doc, err := ParseDocument(strings.NewReader(sampleExample))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	if got := string(doc.Render(nil)); got != sampleExample {
+		t.Fatalf("round trip mismatch:\n%s", got)
+	}
+}
+
+func TestDocumentRenderValues(t *testing.T) {
+	doc, err := ParseDocument(strings.NewReader(sampleExample))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	got := string(doc.Render(map[string]string{
+		"PORT":       "8080",
+		"JWT_SECRET": "it's secret",
+		"EXTRA":      "a b",
+	}))
+	want := `# Application settings
+NODE_ENV=development
+PORT=8080 # http port
+
+# Secrets
+export JWT_SECRET="it's secret"
+PEM="-----BEGIN-----
+abc
+-----END-----"
+EXTRA="a b"
+`
+	if got != want {
+		t.Fatalf("unexpected render:\n%s", got)
+	}
+	reparsed, err := Parse(got)
+	if err != nil {
+		t.Fatalf("Parse() rendered output error = %v", err)
+	}
+	if reparsed[3].Key != "PEM" || reparsed[3].Default != "-----BEGIN-----\nabc\n-----END-----" {
+		t.Fatalf("unexpected reparsed entry: %+v", reparsed[3])
+	}
+}
+
+func TestQuoteValueInteriorBlanks(t *testing.T) {
+	for in, want := range map[string]string{"a b": `"a b"`, "a\tb": "\"a\tb\"", "ab": "ab"} {
+		if got := quoteValue(in, 0); got != want {
+			t.Fatalf("quoteValue(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestRenderKeepsDollarLiteral(t *testing.T) {
+	doc, err := ParseDocument(strings.NewReader("OTHER=x\nPASSWORD=\"\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rendered := string(doc.Render(map[string]string{"PASSWORD": "pa$OTHERword"}))
+	if !strings.Contains(rendered, "PASSWORD='pa$OTHERword'\n") {
+		t.Fatalf("unexpected render:\n%s", rendered)
+	}
+	reparsed, err := ParseDocument(strings.NewReader(rendered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expanded, err := reparsed.Expand(nil, nil)
+	if err != nil || expanded["PASSWORD"] != "pa$OTHERword" {
+		t.Fatalf("Expand() = %q, %v", expanded["PASSWORD"], err)
+	}
+}
+
+func TestQuoteValueRoundTrip(t *testing.T) {
+	values := []string{"plain", "", " padded ", "a # b", "#fff", "line1\nline2", `back\slash "q"`, "it's", "`tick`", "a b", "tab\tbed", "pa$OTHERword", "it's $5"}
+	for _, quote := range []rune{0, '"', '\'', '`'} {
+		for _, v := range values {
+			entries, err := Parse("KEY=" + quoteValue(v, quote))
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", quoteValue(v, quote), err)
+			}
+			if entries[0].Default != v {
+				t.Fatalf("quote %q: expected %q, got %q", quote, v, entries[0].Default)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package env
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Var is a single exported variable. Exporters receive variables in the
+// order they appear in the source file.
+type Var struct {
+	Key   string
+	Value string
+}
+
+// Exporter writes variables in a deployment-specific format.
+type Exporter interface {
+	Export(w io.Writer, vars []Var) error
+}
+
+// ExportOptions carries settings used by some formats, such as the
+// metadata of Kubernetes manifests.
+type ExportOptions struct {
+	Name      string
+	Namespace string
+}
+
+var exporters = map[string]func(ExportOptions) Exporter{
+	"json":   func(ExportOptions) Exporter { return JSONExporter{} },
+	"shell":  func(ExportOptions) Exporter { return ShellExporter{} },
+	"docker": func(ExportOptions) Exporter { return DockerExporter{} },
+	"k8s-secret": func(o ExportOptions) Exporter {
+		return KubernetesExporter{Kind: "Secret", Name: o.Name, Namespace: o.Namespace}
+	},
+	"k8s-configmap": func(o ExportOptions) Exporter {
+		return KubernetesExporter{Kind: "ConfigMap", Name: o.Name, Namespace: o.Namespace}
+	},
+}
+
+// RegisterExporter makes an export format available under name.
+func RegisterExporter(name string, factory func(ExportOptions) Exporter) {
+	exporters[name] = factory
+}
+
+func NewExporter(format string, opts ExportOptions) (Exporter, error) {
+	factory, ok := exporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown export format %q (available: %s)", format, strings.Join(ExportFormats(), ", "))
+	}
+	return factory(opts), nil
+}
+
+func ExportFormats() []string {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Vars pairs the document's keys, in order, with their values, falling back
+// to the entry defaults. Keys only present in values are appended sorted.
+func (d *Document) Vars(values map[string]string) []Var {
+	vars := make([]Var, 0, len(values))
+	seen := make(map[string]bool)
+	for _, e := range d.Entries() {
+		if seen[e.Key] {
+			continue
+		}
+		seen[e.Key] = true
+		v, ok := values[e.Key]
+		if !ok {
+			v = e.Default
+		}
+		vars = append(vars, Var{Key: e.Key, Value: v})
+	}
+	extra := make([]string, 0)
+	for k := range values {
+		if !seen[k] {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+	for _, k := range extra {
+		vars = append(vars, Var{Key: k, Value: values[k]})
+	}
+	return vars
+}
+
+// JSONExporter writes a flat JSON object.
+type JSONExporter struct{}
+
+func (JSONExporter) Export(w io.Writer, vars []Var) error {
+	var b strings.Builder
+	b.WriteString("{")
+	for i, v := range vars {
+		key, _ := json.Marshal(v.Key)
+		value, _ := json.Marshal(v.Value)
+		if i > 0 {
+			b.WriteString(",")
+		}
+		fmt.Fprintf(&b, "\n  %s: %s", key, value)
+	}
+	if len(vars) > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ShellExporter writes POSIX shell export statements with single-quoted
+// values, which the shell never expands.
+type ShellExporter struct{}
+
+func (ShellExporter) Export(w io.Writer, vars []Var) error {
+	var b strings.Builder
+	for _, v := range vars {
+		if !isShellName(v.Key) {
+			return fmt.Errorf("%s is not a valid shell variable name", v.Key)
+		}
+		fmt.Fprintf(&b, "export %s=%s\n", v.Key, shellQuote(v.Value))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func isShellName(s string) bool {
+	for i, r := range s {
+		if !isKeyStart(r) && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// DockerExporter writes a file for docker run --env-file. Docker takes
+// everything after '=' literally and has no quoting, so multi-line values
+// cannot be represented.
+type DockerExporter struct{}
+
+func (DockerExporter) Export(w io.Writer, vars []Var) error {
+	var b strings.Builder
+	for _, v := range vars {
+		if strings.ContainsAny(v.Value, "\r\n") {
+			return fmt.Errorf("docker env files cannot hold the multi-line value of %s", v.Key)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", v.Key, v.Value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// KubernetesExporter writes a Secret (base64-encoded data) or ConfigMap
+// manifest.
+type KubernetesExporter struct {
+	Kind      string
+	Name      string
+	Namespace string
+}
+
+func (k KubernetesExporter) Export(w io.Writer, vars []Var) error {
+	if k.Name == "" {
+		return fmt.Errorf("kubernetes %s requires a name", k.Kind)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", k.Kind, yamlString(k.Name))
+	if k.Namespace != "" {
+		fmt.Fprintf(&b, "  namespace: %s\n", yamlString(k.Namespace))
+	}
+	if k.Kind == "Secret" {
+		b.WriteString("type: Opaque\n")
+	}
+	if len(vars) == 0 {
+		b.WriteString("data: {}\n")
+	} else {
+		b.WriteString("data:\n")
+	}
+	for _, v := range vars {
+		value := yamlString(v.Value)
+		if k.Kind == "Secret" {
+			value = base64.StdEncoding.EncodeToString([]byte(v.Value))
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", v.Key, value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// yamlString quotes s as a YAML double-quoted scalar. JSON string syntax is
+// a subset of it.
+func yamlString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
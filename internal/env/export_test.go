@@ -0,0 +1,64 @@
+package env
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var exportVars = []Var{
+	{Key: "PORT", Value: "3000"},
+	{Key: "MSG", Value: "it's $HOME"},
+}
+
+func TestExporters(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"json", "{\n  \"PORT\": \"3000\",\n  \"MSG\": \"it's $HOME\"\n}\n"},
+		{"shell", "export PORT='3000'\nexport MSG='it'\\''s $HOME'\n"},
+		{"docker", "PORT=3000\nMSG=it's $HOME\n"},
+		{"k8s-secret", "apiVersion: v1\nkind: Secret\nmetadata:\n  name: \"app-env\"\n  namespace: \"web\"\ntype: Opaque\ndata:\n  PORT: MzAwMA==\n  MSG: aXQncyAkSE9NRQ==\n"},
+		{"k8s-configmap", "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: \"app-env\"\n  namespace: \"web\"\ndata:\n  PORT: \"3000\"\n  MSG: \"it's $HOME\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			exp, err := NewExporter(tt.format, ExportOptions{Name: "app-env", Namespace: "web"})
+			if err != nil {
+				t.Fatalf("NewExporter() error = %v", err)
+			}
+			var b strings.Builder
+			if err = exp.Export(&b, exportVars); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+			if b.String() != tt.want {
+				t.Fatalf("unexpected output:\n%s", b.String())
+			}
+		})
+	}
+}
+
+func TestExportersRejectUnrepresentableValues(t *testing.T) {
+	var b strings.Builder
+	if err := (DockerExporter{}).Export(&b, []Var{{Key: "PEM", Value: "a\nb"}}); err == nil {
+		t.Fatal("expected docker multi-line error")
+	}
+	if err := (ShellExporter{}).Export(&b, []Var{{Key: "app.name", Value: "x"}}); err == nil {
+		t.Fatal("expected shell variable name error")
+	}
+	if _, err := NewExporter("toml", ExportOptions{}); err == nil {
+		t.Fatal("expected unknown format error")
+	}
+}
+
+func TestJSONExportIsValid(t *testing.T) {
+	var b strings.Builder
+	if err := (JSONExporter{}).Export(&b, []Var{{Key: "PEM", Value: "a\n\"b\""}}); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]string
+	if err := json.Unmarshal([]byte(b.String()), &decoded); err != nil || decoded["PEM"] != "a\n\"b\"" {
+		t.Fatalf("invalid JSON export %q (%v)", b.String(), err)
+	}
+}
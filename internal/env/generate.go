@@ -0,0 +1,119 @@
+package env
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Generator kinds accepted by the @generate annotation. For hex and base64
+// the length is a number of random bytes, as with "openssl rand"; for
+// password it is the number of characters.
+const (
+	GenerateHex       = "hex"
+	GenerateBase64    = "base64"
+	GenerateBase64URL = "base64url"
+	GenerateUUID      = "uuid"
+	GeneratePassword  = "password"
+)
+
+const passwordAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_.!@%^*+="
+
+var defaultGenerateLength = map[string]int{
+	GenerateHex:       32,
+	GenerateBase64:    32,
+	GenerateBase64URL: 32,
+	GenerateUUID:      0,
+	GeneratePassword:  24,
+}
+
+func parseGenerateSpec(spec string) (string, int, error) {
+	kind, rawLen, hasLen := strings.Cut(spec, ":")
+	n, ok := defaultGenerateLength[kind]
+	if !ok {
+		return "", 0, fmt.Errorf("unknown generator %q", kind)
+	}
+	if !hasLen {
+		return kind, n, nil
+	}
+	if kind == GenerateUUID {
+		return "", 0, fmt.Errorf("generator uuid does not take a length")
+	}
+	n, err := strconv.Atoi(rawLen)
+	if err != nil || n < 1 || n > 4096 {
+		return "", 0, fmt.Errorf("invalid length %q for generator %s", rawLen, kind)
+	}
+	return kind, n, nil
+}
+
+// Generate produces a cryptographically random value for a @generate spec
+// such as "hex:32", "base64:48", "uuid" or "password:24".
+func Generate(spec string) (string, error) {
+	kind, n, err := parseGenerateSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	switch kind {
+	case GeneratePassword:
+		return randomPassword(n)
+	case GenerateUUID:
+		b, err := randomBytes(16)
+		if err != nil {
+			return "", err
+		}
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+	}
+	b, err := randomBytes(n)
+	if err != nil {
+		return "", err
+	}
+	switch kind {
+	case GenerateHex:
+		return hex.EncodeToString(b), nil
+	case GenerateBase64:
+		return base64.StdEncoding.EncodeToString(b), nil
+	default:
+		return base64.RawURLEncoding.EncodeToString(b), nil
+	}
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("read random bytes: %w", err)
+	}
+	return b, nil
+}
+
+func randomPassword(n int) (string, error) {
+	max := big.NewInt(int64(len(passwordAlphabet)))
+	out := make([]byte, n)
+	for i := range out {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("read random bytes: %w", err)
+		}
+		out[i] = passwordAlphabet[idx.Int64()]
+	}
+	return string(out), nil
+}
+
+// Value returns the value a new entry should start with: a freshly
+// generated one when the entry has a @generate annotation, otherwise its
+// default.
+func (e Entry) Value() (string, error) {
+	if e.Generate == "" {
+		return e.Default, nil
+	}
+	v, err := Generate(e.Generate)
+	if err != nil {
+		return "", fmt.Errorf("generate %s: %w", e.Key, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,63 @@
+package env
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		spec  string
+		check func(string) bool
+	}{
+		{"hex", regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString},
+		{"hex:16", regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString},
+		{"base64:48", func(v string) bool {
+			b, err := base64.StdEncoding.DecodeString(v)
+			return err == nil && len(b) == 48
+		}},
+		{"base64url:32", func(v string) bool {
+			b, err := base64.RawURLEncoding.DecodeString(v)
+			return err == nil && len(b) == 32
+		}},
+		{"uuid", regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString},
+		{"password:40", func(v string) bool {
+			return len(v) == 40 && strings.Trim(v, passwordAlphabet) == ""
+		}},
+	}
+	for _, tt := range tests {
+		first, err := Generate(tt.spec)
+		if err != nil {
+			t.Fatalf("Generate(%q) error = %v", tt.spec, err)
+		}
+		if !tt.check(first) {
+			t.Fatalf("Generate(%q) produced unexpected value %q", tt.spec, first)
+		}
+		second, _ := Generate(tt.spec)
+		if first == second {
+			t.Fatalf("Generate(%q) returned the same value twice", tt.spec)
+		}
+	}
+}
+
+func TestGenerateAnnotation(t *testing.T) {
+	entries, err := Parse("# @generate=hex:32\nJWT_SECRET=changeme\nPLAIN=x\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	v, err := entries[0].Value()
+	if err != nil || len(v) != 64 || v == "changeme" {
+		t.Fatalf("unexpected generated value %q (err %v)", v, err)
+	}
+	if v, _ = entries[1].Value(); v != "x" {
+		t.Fatalf("expected default for entry without generator, got %q", v)
+	}
+
+	for _, bad := range []string{"# @generate=md5\nA=", "# @generate=uuid:4\nA=", "# @generate=hex:0\nA="} {
+		if _, err := Parse(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
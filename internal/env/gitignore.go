@@ -0,0 +1,67 @@
+package env
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GitignoreFile lists the files git must not stage.
+const GitignoreFile = ".gitignore"
+
+// SecretPatterns returns the .gitignore patterns of the files that hold
+// plaintext secrets: the env file target, its backups and keyFile. A key
+// file outside the project is left out.
+func SecretPatterns(target, keyFile string) []string {
+	patterns := []string{"/" + filepath.ToSlash(target), "/" + filepath.ToSlash(target) + ".bak.*"}
+	if keyFile != "" && filepath.IsLocal(keyFile) {
+		patterns = append(patterns, "/"+filepath.ToSlash(filepath.Clean(keyFile)))
+	}
+	return patterns
+}
+
+// EnsureIgnored appends the patterns that are missing from the .gitignore
+// at path, creating it if needed, and returns the ones it added. A pattern
+// written with or without its leading slash counts as present.
+func EnsureIgnored(path string, patterns ...string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	present := make(map[string]bool)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		present[strings.TrimPrefix(strings.TrimSpace(sc.Text()), "/")] = true
+	}
+	added := make([]string, 0)
+	var b strings.Builder
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		b.WriteByte('\n')
+	}
+	for _, p := range patterns {
+		if present[strings.TrimPrefix(p, "/")] {
+			continue
+		}
+		present[strings.TrimPrefix(p, "/")] = true
+		added = append(added, p)
+		b.WriteString(p + "\n")
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("update %s: %w", path, err)
+	}
+	if _, err = f.WriteString(b.String()); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("update %s: %w", path, err)
+	}
+	if err = f.Close(); err != nil {
+		return nil, fmt.Errorf("update %s: %w", path, err)
+	}
+	return added, nil
+}
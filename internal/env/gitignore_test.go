@@ -0,0 +1,39 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretPatterns(t *testing.T) {
+	got := strings.Join(SecretPatterns(".env.test", ".env.key"), " ")
+	if want := "/.env.test /.env.test.bak.* /.env.key"; got != want {
+		t.Fatalf("SecretPatterns() = %q, want %q", got, want)
+	}
+	if got := SecretPatterns(".env", filepath.Join(t.TempDir(), "key")); len(got) != 2 {
+		t.Fatalf("expected a key file outside the project to be left out, got %v", got)
+	}
+}
+
+func TestEnsureIgnored(t *testing.T) {
+	path := filepath.Join(t.TempDir(), GitignoreFile)
+	if err := os.WriteFile(path, []byte("node_modules\n.env"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	added, err := EnsureIgnored(path, SecretPatterns(".env", DefaultKeyFile)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(added, " "); got != "/.env.bak.* /.env.key" {
+		t.Fatalf("added = %q", got)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "node_modules\n.env\n/.env.bak.*\n/.env.key\n"; string(data) != want {
+		t.Fatalf(".gitignore = %q, want %q", data, want)
+	}
+	if added, err = EnsureIgnored(path, SecretPatterns(".env", DefaultKeyFile)...); err != nil || len(added) != 0 {
+		t.Fatalf("second call added %v, %v", added, err)
+	}
+}
@@ -0,0 +1,206 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Expand resolves ${VAR}, ${VAR:-fallback}, ${VAR-fallback} and $VAR
+// references in values against the other document entries and, when lookup
+// is not nil, the process environment. Entries missing from values use their
+// defaults, and single-quoted defaults are never expanded.
+func (d *Document) Expand(values map[string]string, lookup func(string) (string, bool)) (map[string]string, error) {
+	return d.ExpandKeys(values, lookup, nil)
+}
+
+// ExpandKeys is Expand limited to keys, so a broken reference elsewhere in
+// the document only matters when one of keys depends on it. A nil keys
+// expands every entry.
+func (d *Document) ExpandKeys(values map[string]string, lookup func(string) (string, bool), keys []string) (map[string]string, error) {
+	x := newExpander(lookup)
+	for _, e := range d.Entries() {
+		x.raw[e.Key] = e.Default
+		x.literal[e.Key] = e.Literal
+	}
+	for k, v := range values {
+		x.raw[k] = v
+	}
+	if keys == nil {
+		keys = make([]string, 0, len(x.raw))
+		for k := range x.raw {
+			keys = append(keys, k)
+		}
+	} else {
+		keys = slices.Clone(keys)
+	}
+	sort.Strings(keys)
+	out := make(map[string]string, len(keys))
+	for _, k := range keys {
+		v, _, err := x.resolve(k)
+		if err != nil {
+			return nil, fmt.Errorf("expand %s: %w", k, err)
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// References lists the variable names referenced by value, in order of
+// appearance and including names only used in fallbacks.
+func References(value string) []string {
+	refs := make([]string, 0)
+	x := newExpander(func(name string) (string, bool) {
+		if !containsString(refs, name) {
+			refs = append(refs, name)
+		}
+		return "", false
+	})
+	_, _ = x.expand(value)
+	return refs
+}
+
+type expander struct {
+	raw      map[string]string
+	literal  map[string]bool
+	lookup   func(string) (string, bool)
+	done     map[string]string
+	visiting []string
+}
+
+func newExpander(lookup func(string) (string, bool)) *expander {
+	return &expander{
+		raw:     map[string]string{},
+		literal: map[string]bool{},
+		lookup:  lookup,
+		done:    map[string]string{},
+	}
+}
+
+func (x *expander) resolve(key string) (string, bool, error) {
+	if v, ok := x.done[key]; ok {
+		return v, true, nil
+	}
+	raw, ok := x.raw[key]
+	if !ok {
+		if x.lookup != nil {
+			if v, ok := x.lookup(key); ok {
+				return v, true, nil
+			}
+		}
+		return "", false, nil
+	}
+	if x.literal[key] {
+		x.done[key] = raw
+		return raw, true, nil
+	}
+	for i, k := range x.visiting {
+		if k == key {
+			cycle := append(append([]string{}, x.visiting[i:]...), key)
+			return "", false, fmt.Errorf("interpolation cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	x.visiting = append(x.visiting, key)
+	v, err := x.expand(raw)
+	x.visiting = x.visiting[:len(x.visiting)-1]
+	if err != nil {
+		return "", false, err
+	}
+	x.done[key] = v
+	return v, true, nil
+}
+
+func (x *expander) expand(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		if s[i] != '$' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			i++
+			continue
+		}
+		switch {
+		case s[i+1] == '{':
+			end := closingBrace(s, i+2)
+			if end < 0 {
+				return "", errors.New("unterminated ${ reference")
+			}
+			v, err := x.expandBraced(s[i+2 : end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			i = end + 1
+		case isKeyStart(rune(s[i+1])):
+			j := i + 1
+			for j < len(s) && isRefPart(rune(s[j])) && s[j] != '.' {
+				j++
+			}
+			v, _, err := x.resolve(s[i+1 : j])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(v)
+			i = j
+		default:
+			b.WriteByte('$')
+			i++
+		}
+	}
+	return b.String(), nil
+}
+
+func (x *expander) expandBraced(inner string) (string, error) {
+	n := 0
+	for n < len(inner) && isRefPart(rune(inner[n])) {
+		n++
+	}
+	name, rest := inner[:n], inner[n:]
+	if name == "" || !isKeyStart(rune(name[0])) {
+		return "", errors.New("invalid name in ${} reference")
+	}
+	v, ok, err := x.resolve(name)
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case rest == "":
+		return v, nil
+	case strings.HasPrefix(rest, ":-"):
+		if !ok || v == "" {
+			return x.expand(rest[2:])
+		}
+		return v, nil
+	case strings.HasPrefix(rest, "-"):
+		if !ok {
+			return x.expand(rest[1:])
+		}
+		return v, nil
+	default:
+		return "", errors.New("unsupported operator in ${} reference, use :- or -")
+	}
+}
+
+// closingBrace returns the index of the '}' matching an opening "${" whose
+// body starts at from, allowing nested references in fallbacks.
+func closingBrace(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch {
+		case s[i] == '$' && i+1 < len(s) && s[i+1] == '{':
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isRefPart(r rune) bool {
+	return isKeyStart(r) || (r >= '0' && r <= '9') || r == '.'
+}
@@ -0,0 +1,105 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	doc, err := ParseDocument(strings.NewReader(`PORT=3000
+HOST=localhost
+API_URL=http://${HOST}:${PORT}/api
+WS_URL=ws://$HOST:$PORT
+LOG_DIR=${LOG_ROOT:-/var/log}/app
+EMPTY=
+WITH_EMPTY=${EMPTY:-fallback}|${EMPTY-unset}|${MISSING-unset}
+NESTED=${MISSING:-${HOST}}
+LITERAL='${PORT}'
+PRICE=$5 and $
+FROM_OS=${ILAUNCH_TEST_HOME}
+`))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	lookup := func(key string) (string, bool) {
+		if key == "ILAUNCH_TEST_HOME" {
+			return "/home/dev", true
+		}
+		return "", false
+	}
+	got, err := doc.Expand(map[string]string{"PORT": "8080"}, lookup)
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	want := map[string]string{
+		"API_URL":    "http://localhost:8080/api",
+		"WS_URL":     "ws://localhost:8080",
+		"LOG_DIR":    "/var/log/app",
+		"WITH_EMPTY": "fallback||unset",
+		"NESTED":     "localhost",
+		"LITERAL":    "${PORT}",
+		"PRICE":      "$5 and $",
+		"FROM_OS":    "/home/dev",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("%s: expected %q, got %q", k, v, got[k])
+		}
+	}
+
+	got, err = doc.Expand(nil, nil)
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if got["FROM_OS"] != "" {
+		t.Fatalf("process environment must be ignored without lookup, got %q", got["FROM_OS"])
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{"A=${B}\nB=${C}\nC=${A}\n", "expand A: interpolation cycle: A -> B -> C -> A"},
+		{"A=$A\n", "expand A: interpolation cycle: A -> A"},
+		{"A=${B\n", "expand A: unterminated ${ reference"},
+		{"A=${1B}\n", "expand A: invalid name in ${} reference"},
+		{"A=${B:=x}\n", "expand A: unsupported operator in ${} reference, use :- or -"},
+		{"A=${B:-s3cr3t\n", "expand A: unterminated ${ reference"},
+	}
+	for _, tt := range tests {
+		doc, err := ParseDocument(strings.NewReader(tt.input))
+		if err != nil {
+			t.Fatalf("ParseDocument() error = %v", err)
+		}
+		_, err = doc.Expand(nil, nil)
+		if err == nil || err.Error() != tt.wantErr {
+			t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+		}
+	}
+}
+
+func TestReferences(t *testing.T) {
+	got := strings.Join(References("http://${HOST:-${FALLBACK}}:$PORT/${HOST}"), ",")
+	if got != "HOST,FALLBACK,PORT" {
+		t.Fatalf("unexpected references: %s", got)
+	}
+}
+
+func TestExpandKeys(t *testing.T) {
+	doc, err := ParseDocument(strings.NewReader("BROKEN=${OPEN\nPORT=3000\nURL=http://localhost:${PORT}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = doc.Expand(nil, nil); err == nil {
+		t.Fatal("Expand() must report the unterminated reference")
+	}
+	got, err := doc.ExpandKeys(nil, nil, []string{"URL"})
+	if err != nil {
+		t.Fatalf("ExpandKeys() error = %v", err)
+	}
+	if len(got) != 1 || got["URL"] != "http://localhost:3000" {
+		t.Fatalf("ExpandKeys() = %v", got)
+	}
+}
@@ -0,0 +1,230 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError reports a syntax error in a dotenv source at a 1-based line and column.
+type ParseError struct {
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type lexer struct {
+	src  []rune
+	pos  int
+	line int
+	col  int
+}
+
+func newLexer(input string) *lexer {
+	input = strings.TrimPrefix(input, "\ufeff")
+	input = strings.ReplaceAll(input, "\r\n", "\n")
+	return &lexer{src: []rune(input), line: 1, col: 1}
+}
+
+func (l *lexer) eof() bool {
+	return l.pos >= len(l.src)
+}
+
+func (l *lexer) peek() rune {
+	if l.eof() {
+		return 0
+	}
+	return l.src[l.pos]
+}
+
+func (l *lexer) next() rune {
+	r := l.src[l.pos]
+	l.pos++
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+func (l *lexer) errorAt(line, col int, format string, args ...any) error {
+	return &ParseError{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (l *lexer) skipSpaces() bool {
+	skipped := false
+	for !l.eof() && isSpace(l.peek()) {
+		l.next()
+		skipped = true
+	}
+	return skipped
+}
+
+// skipLine consumes the rest of the current line, including the newline,
+// and returns the consumed text without the newline.
+func (l *lexer) skipLine() string {
+	start := l.pos
+	for !l.eof() && l.peek() != '\n' {
+		l.next()
+	}
+	text := string(l.src[start:l.pos])
+	if !l.eof() {
+		l.next()
+	}
+	return text
+}
+
+func (l *lexer) lexEntry() (Node, error) {
+	node := Node{Kind: NodeEntry, Entry: Entry{Line: l.line}}
+	key, err := l.lexKey()
+	if err != nil {
+		return Node{}, err
+	}
+	if key == "export" && isSpace(l.peek()) {
+		l.skipSpaces()
+		if isKeyStart(l.peek()) {
+			if key, err = l.lexKey(); err != nil {
+				return Node{}, err
+			}
+			node.Exported = true
+		}
+	}
+	node.Entry.Key = key
+
+	l.skipSpaces()
+	if l.peek() != '=' {
+		return Node{}, l.errorAt(l.line, l.col, "expected '=' after %s", key)
+	}
+	l.next()
+	spaced := l.skipSpaces()
+
+	switch q := l.peek(); q {
+	case '"', '\'', '`':
+		node.Quote = q
+		node.Entry.Literal = q == '\''
+		node.Entry.Default, err = l.lexQuoted(q)
+	default:
+		node.Entry.Default = l.lexUnquoted(spaced)
+	}
+	if err != nil {
+		return Node{}, err
+	}
+	if node.Comment, err = l.lexLineEnd(); err != nil {
+		return Node{}, err
+	}
+	return node, nil
+}
+
+func (l *lexer) lexKey() (string, error) {
+	if !isKeyStart(l.peek()) {
+		if l.eof() || l.peek() == '\n' {
+			return "", l.errorAt(l.line, l.col, "expected variable name")
+		}
+		return "", l.errorAt(l.line, l.col, "unexpected %q, expected variable name", l.peek())
+	}
+	start := l.pos
+	for !l.eof() && isKeyPart(l.peek()) {
+		l.next()
+	}
+	return string(l.src[start:l.pos]), nil
+}
+
+// lexUnquoted reads a bare value up to the end of the line. A '#' starts an
+// inline comment only when preceded by whitespace, so URL fragments survive.
+func (l *lexer) lexUnquoted(spaced bool) string {
+	var b strings.Builder
+	for !l.eof() {
+		r := l.peek()
+		if r == '\n' || (r == '#' && spaced) {
+			break
+		}
+		spaced = isSpace(r)
+		b.WriteRune(l.next())
+	}
+	return strings.TrimRight(b.String(), " \t")
+}
+
+// lexQuoted reads a value enclosed in q. Single-quoted and backtick values
+// are literal; double-quoted values interpret backslash escapes. All three
+// may span multiple lines.
+func (l *lexer) lexQuoted(q rune) (string, error) {
+	line, col := l.line, l.col
+	l.next()
+	var b strings.Builder
+	for {
+		if l.eof() {
+			return "", l.errorAt(line, col, "unterminated %s value", quoteName(q))
+		}
+		r := l.next()
+		if r == q {
+			return b.String(), nil
+		}
+		if r != '\\' || q != '"' {
+			b.WriteRune(r)
+			continue
+		}
+		if l.eof() {
+			return "", l.errorAt(line, col, "unterminated %s value", quoteName(q))
+		}
+		switch e := l.next(); e {
+		case 'n':
+			b.WriteRune('\n')
+		case 'r':
+			b.WriteRune('\r')
+		case 't':
+			b.WriteRune('\t')
+		case '\n':
+			// Backslash-newline continues the line without a break.
+		case '"', '\\', '$', '\'', '`':
+			b.WriteRune(e)
+		default:
+			b.WriteRune('\\')
+			b.WriteRune(e)
+		}
+	}
+}
+
+// lexLineEnd consumes trailing whitespace, an optional inline comment and
+// the newline after a value. It returns the comment text including '#'.
+func (l *lexer) lexLineEnd() (string, error) {
+	l.skipSpaces()
+	switch r := l.peek(); {
+	case l.eof():
+		return "", nil
+	case r == '\n':
+		l.next()
+		return "", nil
+	case r == '#':
+		return l.skipLine(), nil
+	default:
+		return "", l.errorAt(l.line, l.col, "unexpected %q after value", r)
+	}
+}
+
+func quoteName(q rune) string {
+	switch q {
+	case '"':
+		return "double-quoted"
+	case '\'':
+		return "single-quoted"
+	default:
+		return "backtick-quoted"
+	}
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+func isKeyStart(r rune) bool {
+	return r == '_' || (r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')
+}
+
+func isKeyPart(r rune) bool {
+	return isKeyStart(r) || (r >= '0' && r <= '9') || r == '.' || r == '-'
+}
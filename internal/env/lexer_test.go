@@ -0,0 +1,113 @@
+package env
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseGrammar(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Entry
+	}{
+		{"empty", "", nil},
+		{"blank lines and comments", "\n  \n# comment\n\t# indented\n", nil},
+		{"simple", "PORT=3000", []Entry{{Key: "PORT", Default: "3000", Line: 1}}},
+		{"empty value", "EMPTY=", []Entry{{Key: "EMPTY", Default: "", Line: 1}}},
+		{"spaces around equals", "KEY = value  ", []Entry{{Key: "KEY", Default: "value", Line: 1}}},
+		{"leading whitespace", "   KEY=value", []Entry{{Key: "KEY", Default: "value", Line: 1}}},
+		{"export prefix", "export KEY=value", []Entry{{Key: "KEY", Default: "value", Line: 1}}},
+		{"export with tabs", "export\t\tKEY=value", []Entry{{Key: "KEY", Default: "value", Line: 1}}},
+		{"key named export", "export=1", []Entry{{Key: "export", Default: "1", Line: 1}}},
+		{"dotted and dashed keys", "app.name-x=demo", []Entry{{Key: "app.name-x", Default: "demo", Line: 1}}},
+		{"inline comment", "KEY=value # comment", []Entry{{Key: "KEY", Default: "value", Line: 1}}},
+		{"inline comment after empty", "KEY= # comment", []Entry{{Key: "KEY", Default: "", Line: 1}}},
+		{"hash without space is value", "URL=http://x/#frag", []Entry{{Key: "URL", Default: "http://x/#frag", Line: 1}}},
+		{"hash at value start", "COLOR=#fff", []Entry{{Key: "COLOR", Default: "#fff", Line: 1}}},
+		{"unquoted keeps inner spaces", "MSG=hello  world", []Entry{{Key: "MSG", Default: "hello  world", Line: 1}}},
+		{"unquoted keeps quotes inside", "MSG=it's", []Entry{{Key: "MSG", Default: "it's", Line: 1}}},
+		{"unquoted keeps backslashes", `RE=a\nb`, []Entry{{Key: "RE", Default: `a\nb`, Line: 1}}},
+		{"equals in value", "DSN=a=b=c", []Entry{{Key: "DSN", Default: "a=b=c", Line: 1}}},
+		{"single quoted", "KEY='  spaced # not comment '", []Entry{{Key: "KEY", Default: "  spaced # not comment ", Line: 1}}},
+		{"single quoted is literal", `KEY='a\nb'`, []Entry{{Key: "KEY", Default: `a\nb`, Line: 1}}},
+		{"double quoted", `KEY="hello world"`, []Entry{{Key: "KEY", Default: "hello world", Line: 1}}},
+		{"double quoted escapes", `KEY="a\nb\tc\\d\"e\$f"`, []Entry{{Key: "KEY", Default: "a\nb\tc\\d\"e$f", Line: 1}}},
+		{"double quoted unknown escape kept", `KEY="\d+"`, []Entry{{Key: "KEY", Default: `\d+`, Line: 1}}},
+		{"double quoted line continuation", "KEY=\"a\\\nb\"", []Entry{{Key: "KEY", Default: "ab", Line: 1}}},
+		{"backtick quoted", "KEY=`say \"hi\" it's`", []Entry{{Key: "KEY", Default: `say "hi" it's`, Line: 1}}},
+		{"empty quotes", `KEY=""`, []Entry{{Key: "KEY", Default: "", Line: 1}}},
+		{"quoted with comment", `KEY="v" # note`, []Entry{{Key: "KEY", Default: "v", Line: 1}}},
+		{"quoted with adjacent comment", `KEY="v"#note`, []Entry{{Key: "KEY", Default: "v", Line: 1}}},
+		{
+			"multi-line double quoted",
+			"KEY=\"-----BEGIN KEY-----\nabc\n-----END KEY-----\"\nNEXT=1",
+			[]Entry{
+				{Key: "KEY", Default: "-----BEGIN KEY-----\nabc\n-----END KEY-----", Line: 1},
+				{Key: "NEXT", Default: "1", Line: 4},
+			},
+		},
+		{
+			"multi-line single quoted json",
+			"JSON='{\n  \"a\": 1\n}'",
+			[]Entry{{Key: "JSON", Default: "{\n  \"a\": 1\n}", Line: 1}},
+		},
+		{"crlf line endings", "A=1\r\nB=2\r\n", []Entry{{Key: "A", Default: "1", Line: 1}, {Key: "B", Default: "2", Line: 2}}},
+		{"byte order mark", "\ufeffA=1", []Entry{{Key: "A", Default: "1", Line: 1}}},
+		{"unicode value", "GREETING=héllo wörld", []Entry{{Key: "GREETING", Default: "héllo wörld", Line: 1}}},
+		{
+			"file order preserved",
+			"B=2\n# c\nA=1\n",
+			[]Entry{{Key: "B", Default: "2", Line: 1}, {Key: "A", Default: "1", Line: 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d entries, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i := range got {
+				if got[i].Key != tt.want[i].Key || got[i].Default != tt.want[i].Default || got[i].Line != tt.want[i].Line {
+					t.Fatalf("entry %d: expected %+v, got %+v", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseGrammarErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		line   int
+		column int
+	}{
+		{"missing equals", "INVALID", 1, 8},
+		{"missing equals after spaces", "KEY value", 1, 5},
+		{"bad key start", "1KEY=v", 1, 1},
+		{"bad key character", "KE Y=v", 1, 4},
+		{"export without assignment", "export KEY", 1, 11},
+		{"unterminated double quote", "A=1\nKEY=\"abc\nmore", 2, 5},
+		{"unterminated single quote", "KEY='abc", 1, 5},
+		{"unterminated backtick", "KEY=`abc", 1, 5},
+		{"trailing escape", `KEY="abc\`, 1, 5},
+		{"garbage after quote", `KEY="abc" def`, 1, 11},
+		{"error column counts runes", "KEY=\"é\" x", 1, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected ParseError, got %v", err)
+			}
+			if perr.Line != tt.line || perr.Column != tt.column {
+				t.Fatalf("expected position %d:%d, got %d:%d (%v)", tt.line, tt.column, perr.Line, perr.Column, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package env
+
+import "slices"
+
+// MergeResult describes how an existing .env relates to its template.
+// Values holds every existing value, including obsolete ones, so that a
+// merge never drops user data; Added lists template entries that still
+// need a value.
+type MergeResult struct {
+	Values   map[string]string
+	Added    []Entry
+	Obsolete []string
+}
+
+func Merge(template *Document, existing []Entry) MergeResult {
+	res := MergeResult{Values: make(map[string]string, len(existing))}
+	declared := make(map[string]bool)
+	for _, e := range template.Entries() {
+		declared[e.Key] = true
+	}
+	for _, e := range existing {
+		if _, dup := res.Values[e.Key]; !dup && !declared[e.Key] {
+			res.Obsolete = append(res.Obsolete, e.Key)
+		}
+		res.Values[e.Key] = e.Default
+	}
+	for _, e := range template.Entries() {
+		if _, ok := res.Values[e.Key]; !ok {
+			res.Added = append(res.Added, e)
+		}
+	}
+	return res
+}
+
+// Extend returns existing followed by the template nodes of added, in
+// template order. Writing the result keeps the comments, ordering and
+// formatting of the existing file and only appends the new keys.
+func Extend(existing, template *Document, added []Entry) *Document {
+	want := make(map[string]bool, len(added))
+	for _, e := range added {
+		want[e.Key] = true
+	}
+	out := &Document{Nodes: slices.Clone(existing.Nodes)}
+	for _, n := range template.Nodes {
+		if n.Kind == NodeEntry && want[n.Entry.Key] {
+			out.Nodes = append(out.Nodes, n)
+			want[n.Entry.Key] = false
+		}
+	}
+	return out
+}
@@ -0,0 +1,29 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	template, err := ParseDocument(strings.NewReader("PORT=3000\n# new\nREDIS_URL=redis://localhost\nNODE_ENV=development\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	existing, err := ParseDocument(strings.NewReader("# mine\nNODE_ENV=production\n\nPORT='8080' # http\nLEGACY=1\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	res := Merge(template, existing.Entries())
+	if len(res.Added) != 1 || res.Added[0].Key != "REDIS_URL" {
+		t.Fatalf("unexpected added entries: %+v", res.Added)
+	}
+	if len(res.Obsolete) != 1 || res.Obsolete[0] != "LEGACY" {
+		t.Fatalf("unexpected obsolete keys: %v", res.Obsolete)
+	}
+	res.Values["REDIS_URL"] = "redis://cache"
+	want := "# mine\nNODE_ENV=production\n\nPORT='8080' # http\nLEGACY=1\nREDIS_URL=redis://cache\n"
+	if got := string(Extend(existing, template, res.Added).Render(res.Values)); got != want {
+		t.Fatalf("unexpected merged output:\n%s", got)
+	}
+}
@@ -1,42 +1,43 @@
 package env
 
 import (
-	"bufio"
-	"fmt"
 	"io"
-	"sort"
-	"strings"
 )
 
+// Entry is a variable declared in a dotenv source. The schema fields are
+// filled from "# @name=value" annotation comments directly above it.
+// Literal is set for single-quoted values, which are never interpolated.
 type Entry struct {
-	Key     string
-	Default string
+	Key         string
+	Default     string
+	Line        int
+	Literal     bool
+	Type        string
+	Required    bool
+	Enum        []string
+	Pattern     string
+	Min         string
+	Max         string
+	Schemes     []string
+	Description string
+	Secret      bool
+	Generate    string
 }
 
+// ParseExample reads a .env.example source and returns its entries in file order.
 func ParseExample(r io.Reader) ([]Entry, error) {
-	scanner := bufio.NewScanner(r)
-	entries := make([]Entry, 0)
-	lineNum := 0
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-		key, value, ok := strings.Cut(line, "=")
-		if !ok {
-			return nil, fmt.Errorf("invalid line %d: expected KEY=value", lineNum)
-		}
-		key = strings.TrimSpace(key)
-		value = strings.TrimSpace(value)
-		if key == "" {
-			return nil, fmt.Errorf("invalid line %d: empty key", lineNum)
-		}
-		entries = append(entries, Entry{Key: key, Default: value})
+	doc, err := ParseDocument(r)
+	if err != nil {
+		return nil, err
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("scan .env.example: %w", err)
+	return doc.Entries(), nil
+}
+
+// Parse reads dotenv source and returns its assignments in file order.
+func Parse(input string) ([]Entry, error) {
+	doc, err := parseDocument(input)
+	if err != nil {
+		return nil, err
 	}
-	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
-	return entries, nil
+	return doc.Entries(), nil
 }
@@ -18,7 +18,7 @@ API_URL=https://example.com
 	if len(entries) != 2 {
 		t.Fatalf("expected 2 entries, got %d", len(entries))
 	}
-	if entries[0].Key != "API_URL" || entries[0].Default != "https://example.com" {
+	if entries[0].Key != "PORT" || entries[0].Default != "3000" {
 		t.Fatalf("unexpected first entry: %+v", entries[0])
 	}
 }
@@ -0,0 +1,147 @@
+package env
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ExampleFile is the template every .env file is generated from.
+const ExampleFile = ".env.example"
+
+var profileName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+func ValidateProfile(profile string) error {
+	if profile != "" && !profileName.MatchString(profile) {
+		return fmt.Errorf("invalid profile name %q", profile)
+	}
+	return nil
+}
+
+// ProfileFile returns the file generated for profile: ".env" for the
+// default profile and ".env.<profile>" otherwise.
+func ProfileFile(profile string) string {
+	if profile == "" {
+		return ".env"
+	}
+	return ".env." + profile
+}
+
+// OverlayFile returns the optional template overlay for profile.
+func OverlayFile(profile string) string {
+	return ExampleFile + "." + profile
+}
+
+// DiscoverProfiles lists the profiles that have an overlay in dir.
+func DiscoverProfiles(dir string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, ExampleFile+".*"))
+	if err != nil {
+		return nil, fmt.Errorf("find profile overlays: %w", err)
+	}
+	profiles := make([]string, 0, len(matches))
+	for _, m := range matches {
+		profile := strings.TrimPrefix(filepath.Base(m), ExampleFile+".")
+		if profileName.MatchString(profile) {
+			profiles = append(profiles, profile)
+		}
+	}
+	sort.Strings(profiles)
+	return profiles, nil
+}
+
+// Overlay returns base with the entries of overlay applied on top. An
+// overlay entry replaces the base entry of the same key in place and
+// inherits any annotation it does not redeclare; entries only present in
+// the overlay are appended together with the comments directly above them.
+func Overlay(base, overlay *Document) *Document {
+	index := make(map[string]int)
+	out := &Document{Nodes: append([]Node(nil), base.Nodes...)}
+	for i, n := range out.Nodes {
+		if n.Kind == NodeEntry {
+			index[n.Entry.Key] = i
+		}
+	}
+
+	var extra, block []Node
+	for _, n := range overlay.Nodes {
+		switch n.Kind {
+		case NodeComment:
+			block = append(block, n)
+		case NodeBlank:
+			block = nil
+			if len(extra) > 0 && extra[len(extra)-1].Kind != NodeBlank {
+				extra = append(extra, n)
+			}
+		case NodeEntry:
+			if i, ok := index[n.Entry.Key]; ok {
+				n.Entry = inheritSchema(n.Entry, out.Nodes[i].Entry, annotationNames(block))
+				out.Nodes[i] = n
+			} else {
+				extra = append(append(extra, block...), n)
+			}
+			block = nil
+		}
+	}
+	for len(extra) > 0 && extra[len(extra)-1].Kind == NodeBlank {
+		extra = extra[:len(extra)-1]
+	}
+	if len(extra) == 0 {
+		return out
+	}
+	if last := len(out.Nodes) - 1; last >= 0 && out.Nodes[last].Kind != NodeBlank {
+		out.Nodes = append(out.Nodes, Node{Kind: NodeBlank})
+	}
+	out.Nodes = append(out.Nodes, extra...)
+	return out
+}
+
+// inheritSchema fills in the annotations of the overlay entry e from the
+// base entry it replaces. Flags such as @required come from the base unless
+// the overlay declares them, so @required=false can relax the base.
+func inheritSchema(e, base Entry, declared map[string]bool) Entry {
+	if e.Type == "" {
+		e.Type = base.Type
+	}
+	if len(e.Enum) == 0 {
+		e.Enum = base.Enum
+	}
+	if e.Pattern == "" {
+		e.Pattern = base.Pattern
+	}
+	if e.Min == "" {
+		e.Min = base.Min
+	}
+	if e.Max == "" {
+		e.Max = base.Max
+	}
+	if len(e.Schemes) == 0 {
+		e.Schemes = base.Schemes
+	}
+	if e.Description == "" {
+		e.Description = base.Description
+	}
+	if e.Generate == "" {
+		e.Generate = base.Generate
+	}
+	if !declared["required"] {
+		e.Required = base.Required
+	}
+	if !declared["secret"] {
+		e.Secret = base.Secret
+	}
+	return e
+}
+
+// annotationNames returns the annotations declared by the comment block
+// directly above an entry.
+func annotationNames(block []Node) map[string]bool {
+	names := make(map[string]bool)
+	for _, n := range block {
+		if a, ok := parseAnnotation(strings.TrimSpace(n.Raw), 0, 0); ok {
+			names[a.name] = true
+		}
+	}
+	return names
+}
@@ -0,0 +1,88 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	base, err := ParseDocument(strings.NewReader("# App\n# @enum=development,test,production\nNODE_ENV=development\nPORT=3000\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	overlay, err := ParseDocument(strings.NewReader("NODE_ENV=test\n\n# Test database\nDATABASE_URL=postgres://localhost/test\n"))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+	doc := Overlay(base, overlay)
+	want := "# App\n# @enum=development,test,production\nNODE_ENV=test\nPORT=3000\n\n# Test database\nDATABASE_URL=postgres://localhost/test\n"
+	if got := string(doc.Render(nil)); got != want {
+		t.Fatalf("unexpected overlay render:\n%s", got)
+	}
+	entries := doc.Entries()
+	if len(entries[0].Enum) != 3 {
+		t.Fatalf("overlay entry must inherit base annotations: %+v", entries[0])
+	}
+	if err := entries[0].Validate("staging"); err == nil {
+		t.Fatal("expected inherited enum validation")
+	}
+	if len(base.Entries()) != 2 || base.Entries()[0].Default != "development" {
+		t.Fatal("Overlay must not modify the base document")
+	}
+}
+
+func TestOverlayInheritsSchema(t *testing.T) {
+	base, err := ParseDocument(strings.NewReader("# @min=1024\n# @required\nPORT=3000\n# @scheme=https\nAPI_URL=https://api.example.com\n# @secret\nTOKEN=x\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	overlay, err := ParseDocument(strings.NewReader("PORT=4000\nAPI_URL=http://localhost\n# @secret=false\nTOKEN=test\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := Overlay(base, overlay).Entries()
+	if err := entries[0].Validate("80"); err == nil {
+		t.Fatal("overlay entry must inherit @min")
+	}
+	if !entries[0].Required {
+		t.Fatal("overlay entry must inherit @required")
+	}
+	if err := entries[1].Validate("http://localhost"); err == nil {
+		t.Fatal("overlay entry must inherit @scheme")
+	}
+	if entries[2].Secret {
+		t.Fatal("overlay @secret=false must relax the base")
+	}
+
+	overlay, err = ParseDocument(strings.NewReader("# @required=false\nPORT=\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := Overlay(base, overlay).Entries()[0]; e.Required || e.Validate("") != nil {
+		t.Fatalf("overlay @required=false must relax the base: %+v", e)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	if ProfileFile("") != ".env" || ProfileFile("test") != ".env.test" {
+		t.Fatalf("unexpected profile files")
+	}
+	if err := ValidateProfile("../etc"); err == nil {
+		t.Fatal("expected invalid profile error")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{".env.example", ".env.example.test", ".env.example.production", ".env.example.bad name"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	profiles, err := DiscoverProfiles(dir)
+	if err != nil {
+		t.Fatalf("DiscoverProfiles() error = %v", err)
+	}
+	if strings.Join(profiles, ",") != "production,test" {
+		t.Fatalf("unexpected profiles: %v", profiles)
+	}
+}
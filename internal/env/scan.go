@@ -0,0 +1,160 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Reference is a use of an environment variable found in source code.
+type Reference struct {
+	Key  string
+	File string
+	Line int
+}
+
+var (
+	scanExtensions = map[string]bool{
+		".js": true, ".jsx": true, ".mjs": true, ".cjs": true,
+		".ts": true, ".tsx": true, ".mts": true, ".cts": true,
+		".vue": true, ".svelte": true, ".astro": true,
+	}
+	scanSkipDirs = map[string]bool{
+		"node_modules": true, ".git": true, "dist": true, "build": true, "out": true,
+		"coverage": true, ".next": true, ".nuxt": true, ".svelte-kit": true, ".turbo": true, ".cache": true,
+	}
+	// Variables Vite defines itself on import.meta.env.
+	viteBuiltins = map[string]bool{"MODE": true, "BASE_URL": true, "PROD": true, "DEV": true, "SSR": true}
+
+	envAccess      = regexp.MustCompile(`\b(process\.env|import\.meta\.env)(?:\?\.|\.)([A-Za-z_][A-Za-z0-9_]*)`)
+	envIndex       = regexp.MustCompile("\\b(process\\.env|import\\.meta\\.env)\\??\\.?\\[\\s*[\"'`]([A-Za-z_][A-Za-z0-9_]*)[\"'`]\\s*\\]")
+	envDestructure = regexp.MustCompile(`\{([^{}]*)\}\s*=\s*(process\.env|import\.meta\.env)\b`)
+)
+
+// ScanSource walks root for JavaScript and TypeScript files and returns
+// the first reference to each environment variable, sorted by key.
+func ScanSource(root string) ([]Reference, error) {
+	first := make(map[string]Reference)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && scanSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !scanExtensions[filepath.Ext(path)] || strings.HasSuffix(path, ".min.js") {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			rel = path
+		}
+		refs, err := ScanReader(filepath.ToSlash(rel), file)
+		if err != nil {
+			return err
+		}
+		for _, r := range refs {
+			if _, ok := first[r.Key]; !ok {
+				first[r.Key] = r
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan %s: %w", root, err)
+	}
+	refs := make([]Reference, 0, len(first))
+	for _, r := range first {
+		refs = append(refs, r)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Key < refs[j].Key })
+	return refs, nil
+}
+
+// ScanReader returns every environment variable reference in r, which is
+// reported as file.
+func ScanReader(file string, r io.Reader) ([]Reference, error) {
+	refs := make([]Reference, 0)
+	add := func(source, key string, line int) {
+		if source == "import.meta.env" && viteBuiltins[key] {
+			return
+		}
+		refs = append(refs, Reference{Key: key, File: file, Line: line})
+	}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		for _, m := range envAccess.FindAllStringSubmatch(text, -1) {
+			add(m[1], m[2], line)
+		}
+		for _, m := range envIndex.FindAllStringSubmatch(text, -1) {
+			add(m[1], m[2], line)
+		}
+		for _, m := range envDestructure.FindAllStringSubmatch(text, -1) {
+			for _, field := range strings.Split(m[1], ",") {
+				field = strings.TrimSpace(field)
+				if field == "" || strings.HasPrefix(field, "...") {
+					continue
+				}
+				parts := strings.FieldsFunc(field, func(r rune) bool { return r == ':' || r == '=' })
+				if len(parts) == 0 {
+					continue
+				}
+				if name := strings.TrimSpace(parts[0]); isShellName(name) {
+					add(m[2], name, line)
+				}
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", file, err)
+	}
+	return refs, nil
+}
+
+// AddDiscovered appends the referenced keys the document does not declare
+// yet under a review section, each with an empty value and a comment
+// pointing at its first use. It returns the keys that were added.
+func (d *Document) AddDiscovered(refs []Reference) []string {
+	declared := make(map[string]bool)
+	for _, e := range d.Entries() {
+		declared[e.Key] = true
+	}
+	added := make([]string, 0)
+	var nodes []Node
+	for _, r := range refs {
+		if declared[r.Key] {
+			continue
+		}
+		declared[r.Key] = true
+		added = append(added, r.Key)
+		nodes = append(nodes,
+			Node{Kind: NodeComment, Raw: fmt.Sprintf("# TODO: review %s (used in %s:%d)", r.Key, r.File, r.Line)},
+			Node{Kind: NodeEntry, Entry: Entry{Key: r.Key}, Raw: r.Key + "="},
+		)
+	}
+	if len(nodes) == 0 {
+		return added
+	}
+	if last := len(d.Nodes) - 1; last >= 0 && d.Nodes[last].Kind != NodeBlank {
+		d.Nodes = append(d.Nodes, Node{Kind: NodeBlank})
+	}
+	d.Nodes = append(d.Nodes, Node{Kind: NodeComment, Raw: "# Discovered by ilaunch env scan"})
+	d.Nodes = append(d.Nodes, nodes...)
+	return added
+}
@@ -0,0 +1,83 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanReader(t *testing.T) {
+	src := `const port = process.env.PORT ?? 3000;
+const url = import.meta.env.VITE_API_URL;
+const key = process.env["STRIPE_KEY"] || process.env?.FALLBACK;
+const mode = import.meta.env.MODE;
+const { DB_HOST, DB_PORT: port = 5432, ...rest } = process.env;
+`
+	refs, err := ScanReader("src/app.ts", strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("ScanReader() error = %v", err)
+	}
+	keys := make([]string, 0, len(refs))
+	for _, r := range refs {
+		keys = append(keys, r.Key)
+	}
+	if got := strings.Join(keys, ","); got != "PORT,VITE_API_URL,FALLBACK,STRIPE_KEY,DB_HOST,DB_PORT" {
+		t.Fatalf("unexpected keys: %s", got)
+	}
+	if refs[4].Line != 5 || refs[4].File != "src/app.ts" {
+		t.Fatalf("unexpected location: %+v", refs[4])
+	}
+}
+
+func TestScanSourceAndAddDiscovered(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"src/server.ts":            "listen(process.env.PORT)\nconnect(process.env.REDIS_URL)\n",
+		"src/client.tsx":           "fetch(import.meta.env.VITE_API_URL)\n",
+		"node_modules/x/index.js":  "process.env.IGNORED\n",
+		"README.md":                "process.env.NOT_CODE\n",
+		"src/vendor/lib.min.js":    "process.env.MINIFIED\n",
+		"src/nested/deep/util.mjs": "process.env.REDIS_URL\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	refs, err := ScanSource(root)
+	if err != nil {
+		t.Fatalf("ScanSource() error = %v", err)
+	}
+	if len(refs) != 3 {
+		t.Fatalf("unexpected references: %+v", refs)
+	}
+
+	doc, err := ParseDocument(strings.NewReader("# Server\nPORT=3000\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	added := doc.AddDiscovered(refs)
+	if strings.Join(added, ",") != "REDIS_URL,VITE_API_URL" {
+		t.Fatalf("unexpected added keys: %v", added)
+	}
+	want := `# Server
+PORT=3000
+
+# Discovered by ilaunch env scan
+# TODO: review REDIS_URL (used in src/nested/deep/util.mjs:1)
+REDIS_URL=
+# TODO: review VITE_API_URL (used in src/client.tsx:1)
+VITE_API_URL=
+`
+	if got := string(doc.Render(nil)); got != want {
+		t.Fatalf("unexpected document:\n%s", got)
+	}
+	if _, err := Parse(want); err != nil {
+		t.Fatalf("rendered document does not parse: %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package env
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Value types accepted by the @type annotation.
+const (
+	TypeString   = "string"
+	TypeInt      = "int"
+	TypeNumber   = "number"
+	TypeBool     = "bool"
+	TypePort     = "port"
+	TypeURL      = "url"
+	TypeEmail    = "email"
+	TypeDuration = "duration"
+)
+
+var knownTypes = map[string]bool{
+	TypeString:   true,
+	TypeInt:      true,
+	TypeNumber:   true,
+	TypeBool:     true,
+	TypePort:     true,
+	TypeURL:      true,
+	TypeEmail:    true,
+	TypeDuration: true,
+}
+
+type annotation struct {
+	name   string
+	value  string
+	line   int
+	column int
+}
+
+// parseAnnotation recognizes comment lines of the form "# @name" or
+// "# @name=value". line and column locate the '#' of the comment.
+func parseAnnotation(text string, line, column int) (annotation, bool) {
+	body := strings.TrimPrefix(text, "#")
+	trimmed := strings.TrimLeft(body, " \t")
+	if !strings.HasPrefix(trimmed, "@") {
+		return annotation{}, false
+	}
+	column += 1 + len([]rune(body)) - len([]rune(trimmed))
+	name, value, _ := strings.Cut(strings.TrimSpace(trimmed[1:]), "=")
+	return annotation{
+		name:   strings.TrimSpace(name),
+		value:  strings.TrimSpace(value),
+		line:   line,
+		column: column,
+	}, true
+}
+
+func (a annotation) errorf(format string, args ...any) error {
+	return &ParseError{Line: a.line, Column: a.column, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (a annotation) apply(e *Entry) error {
+	switch a.name {
+	case "type":
+		if !knownTypes[a.value] {
+			return a.errorf("unknown type %q for @type", a.value)
+		}
+		e.Type = a.value
+	case "required":
+		e.Required = true
+		if a.value != "" {
+			required, err := strconv.ParseBool(a.value)
+			if err != nil {
+				return a.errorf("invalid @required value %q", a.value)
+			}
+			e.Required = required
+		}
+	case "enum":
+		e.Enum = nil
+		for _, v := range strings.Split(a.value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				e.Enum = append(e.Enum, v)
+			}
+		}
+		if len(e.Enum) == 0 {
+			return a.errorf("@enum requires at least one value")
+		}
+	case "pattern":
+		if _, err := regexp.Compile(a.value); err != nil {
+			return a.errorf("invalid @pattern: %v", err)
+		}
+		e.Pattern = a.value
+	case "min":
+		e.Min = a.value
+	case "max":
+		e.Max = a.value
+	case "scheme":
+		e.Schemes = nil
+		for _, v := range strings.Split(a.value, ",") {
+			if v = strings.ToLower(strings.TrimSpace(v)); v != "" {
+				e.Schemes = append(e.Schemes, v)
+			}
+		}
+		if len(e.Schemes) == 0 {
+			return a.errorf("@scheme requires at least one value")
+		}
+	case "description":
+		e.Description = a.value
+	case "generate":
+		if _, _, err := parseGenerateSpec(a.value); err != nil {
+			return a.errorf("invalid @generate: %v", err)
+		}
+		e.Generate = a.value
+	case "secret":
+		e.Secret = true
+		if a.value != "" {
+			secret, err := strconv.ParseBool(a.value)
+			if err != nil {
+				return a.errorf("invalid @secret value %q", a.value)
+			}
+			e.Secret = secret
+		}
+	}
+	// Other annotations, such as @see, @todo or @deprecated, are left to
+	// readers of the file.
+	return nil
+}
+
+// Validate checks value against the annotations declared for the entry
+// and the type inferred from its name. Secret values are never included in
+// the returned error.
+func (e Entry) Validate(value string) error {
+	if value == "" {
+		if e.Required {
+			return fmt.Errorf("%s is required", e.Key)
+		}
+		return nil
+	}
+	got := fmt.Sprintf(", got %q", value)
+	if e.IsSecret() {
+		got = ""
+	}
+	typ := e.EffectiveType()
+	if err := validateType(e.Key, typ, value, got); err != nil {
+		return err
+	}
+	if err := validateBounds(e.Key, typ, value, e.Min, e.Max, got); err != nil {
+		return err
+	}
+	if typ == TypeURL && len(e.Schemes) > 0 {
+		u, _ := url.Parse(value)
+		if !containsString(e.Schemes, strings.ToLower(u.Scheme)) {
+			return fmt.Errorf("%s must use scheme %s%s", e.Key, strings.Join(e.Schemes, " or "), got)
+		}
+	}
+	if len(e.Enum) > 0 && !containsString(e.Enum, value) {
+		return fmt.Errorf("%s must be one of %s%s", e.Key, strings.Join(e.Enum, ", "), got)
+	}
+	if e.Pattern != "" {
+		re, err := regexp.Compile(e.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s has invalid pattern: %w", e.Key, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s must match pattern %s%s", e.Key, e.Pattern, got)
+		}
+	}
+	return nil
+}
+
+// checkSchema reports annotations that contradict each other once the
+// whole block above an entry has been applied.
+func (e Entry) checkSchema() error {
+	typ := e.EffectiveType()
+	for _, bound := range []struct{ name, value string }{{"min", e.Min}, {"max", e.Max}} {
+		if bound.value == "" {
+			continue
+		}
+		switch typ {
+		case TypeInt, TypePort, TypeNumber, TypeDuration:
+			if _, err := parseBound(typ, bound.value); err != nil {
+				return fmt.Errorf("invalid @%s %q for type %s", bound.name, bound.value, typ)
+			}
+		default:
+			return fmt.Errorf("@%s requires a numeric or duration @type", bound.name)
+		}
+	}
+	if len(e.Schemes) > 0 && typ != TypeURL {
+		return fmt.Errorf("@scheme requires @type=url")
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
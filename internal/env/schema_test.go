@@ -0,0 +1,109 @@
+package env
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	entries, err := Parse(`# Server
+# @type=port
+# @required
+# @description=HTTP listen port
+PORT=3000
+
+# @enum=development, production,test
+NODE_ENV=development
+# @required
+
+# @see https://example.com/api
+# @todo switch to https
+# @pattern=^https?://
+# @deprecated
+API_URL=http://localhost
+`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	port := entries[0]
+	if port.Type != TypePort || !port.Required || port.Description != "HTTP listen port" {
+		t.Fatalf("unexpected port schema: %+v", port)
+	}
+	if got := strings.Join(entries[1].Enum, "|"); got != "development|production|test" {
+		t.Fatalf("unexpected enum: %s", got)
+	}
+	if entries[2].Pattern != "^https?://" {
+		t.Fatalf("expected pattern on API_URL, got %q", entries[2].Pattern)
+	}
+	if entries[1].Required || entries[2].Required {
+		t.Fatal("annotation before a blank line must not attach to any entry")
+	}
+}
+
+func TestParseAnnotationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		line   int
+		column int
+	}{
+		{"unknown type", "#   @type=uuidish\nA=1", 1, 5},
+		{"empty enum", "# @enum= , \nA=1", 1, 3},
+		{"bad pattern", "A=1\n# @pattern=([a-z\nB=2", 2, 3},
+		{"bad required", "# @required=maybe\nA=1", 1, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("expected ParseError, got %v", err)
+			}
+			if perr.Line != tt.line || perr.Column != tt.column {
+				t.Fatalf("expected position %d:%d, got %d:%d (%v)", tt.line, tt.column, perr.Line, perr.Column, err)
+			}
+		})
+	}
+}
+
+func TestEntryValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   Entry
+		value   string
+		wantErr string
+	}{
+		{"optional empty", Entry{Key: "A"}, "", ""},
+		{"required empty", Entry{Key: "A", Required: true}, "", "A is required"},
+		{"int ok", Entry{Key: "N", Type: TypeInt}, "-4", ""},
+		{"int bad", Entry{Key: "N", Type: TypeInt}, "4.5", `N must be an integer, got "4.5"`},
+		{"number ok", Entry{Key: "N", Type: TypeNumber}, "4.5", ""},
+		{"bool ok", Entry{Key: "B", Type: TypeBool}, "false", ""},
+		{"bool bad", Entry{Key: "B", Type: TypeBool}, "yes please", `B must be a boolean (true/false, yes/no, on/off, 1/0), got "yes please"`},
+		{"port ok", Entry{Key: "PORT", Type: TypePort}, "3000", ""},
+		{"port range", Entry{Key: "PORT", Type: TypePort}, "70000", `PORT must be a port number between 1 and 65535, got "70000"`},
+		{"url ok", Entry{Key: "U", Type: TypeURL}, "https://example.com/x", ""},
+		{"url relative", Entry{Key: "U", Type: TypeURL}, "/x", `U must be an absolute URL, got "/x"`},
+		{"email ok", Entry{Key: "E", Type: TypeEmail}, "dev@example.com", ""},
+		{"email bad", Entry{Key: "E", Type: TypeEmail}, "Dev <dev@example.com>", `E must be an email address, got "Dev <dev@example.com>"`},
+		{"enum ok", Entry{Key: "ENV", Enum: []string{"dev", "prod"}}, "prod", ""},
+		{"enum bad", Entry{Key: "ENV", Enum: []string{"dev", "prod"}}, "qa", `ENV must be one of dev, prod, got "qa"`},
+		{"pattern ok", Entry{Key: "U", Pattern: "^https?://"}, "http://x", ""},
+		{"pattern bad", Entry{Key: "U", Pattern: "^https?://"}, "ftp://x", `U must match pattern ^https?://, got "ftp://x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.entry.Validate(tt.value)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package env
+
+import "strings"
+
+var secretSuffixes = []string{"SECRET", "TOKEN", "PASSWORD", "PASSWD", "PRIVATE_KEY", "API_KEY"}
+
+// IsSecretKey reports whether key looks like it holds a credential, based
+// on naming conventions such as JWT_SECRET or DB_PASSWORD.
+func IsSecretKey(key string) bool {
+	upper := strings.ToUpper(key)
+	for _, suffix := range secretSuffixes {
+		if upper == suffix || strings.HasSuffix(upper, "_"+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSecret reports whether the entry is annotated with @secret or named
+// like a credential.
+func (e Entry) IsSecret() bool {
+	return e.Secret || IsSecretKey(e.Key)
+}
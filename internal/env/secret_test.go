@@ -0,0 +1,45 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSecret(t *testing.T) {
+	tests := []struct {
+		entry Entry
+		want  bool
+	}{
+		{Entry{Key: "JWT_SECRET"}, true},
+		{Entry{Key: "github_token"}, true},
+		{Entry{Key: "DB_PASSWORD"}, true},
+		{Entry{Key: "STRIPE_API_KEY"}, true},
+		{Entry{Key: "TOKEN"}, true},
+		{Entry{Key: "TOKEN_URL"}, false},
+		{Entry{Key: "PORT"}, false},
+		{Entry{Key: "DATABASE_URL", Secret: true}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.IsSecret(); got != tt.want {
+			t.Fatalf("IsSecret(%s) = %v, want %v", tt.entry.Key, got, tt.want)
+		}
+	}
+}
+
+func TestSecretAnnotationAndRedactedValidation(t *testing.T) {
+	entries, err := Parse("# @secret\n# @pattern=^postgres://\nDATABASE_URL=mysql://user:hunter2@db\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	e := entries[0]
+	if !e.IsSecret() {
+		t.Fatal("expected @secret entry")
+	}
+	err = e.Validate(e.Default)
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("secret value leaked in error: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package env
+
+import (
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EffectiveType returns the declared @type or, when there is none, a type
+// inferred from naming conventions (PORT, *_URL, *_TIMEOUT, ...). An
+// inferred type is only used if the example default satisfies it, so
+// unusual values like a relative API_URL keep working.
+func (e Entry) EffectiveType() string {
+	if e.Type != "" {
+		return e.Type
+	}
+	typ := inferType(e.Key)
+	if typ != "" && e.Default != "" && validateType(e.Key, typ, e.Default, "") != nil {
+		return ""
+	}
+	return typ
+}
+
+func inferType(key string) string {
+	k := strings.ToUpper(key)
+	switch {
+	case k == "PORT" || strings.HasSuffix(k, "_PORT"):
+		return TypePort
+	case strings.HasSuffix(k, "_URL") || strings.HasSuffix(k, "_URI"):
+		return TypeURL
+	case k == "EMAIL" || strings.HasSuffix(k, "_EMAIL"):
+		return TypeEmail
+	case strings.HasSuffix(k, "_TIMEOUT") || strings.HasSuffix(k, "_TTL") || strings.HasSuffix(k, "_INTERVAL"):
+		return TypeDuration
+	}
+	return ""
+}
+
+// Normalize returns the canonical spelling of value for the entry type:
+// for an explicit @type=bool, "yes", "On" or "1" become "true" or "false".
+// Booleans are never inferred from the key name, so a value such as 1 is
+// only rewritten when the template asks for it.
+func (e Entry) Normalize(value string) string {
+	if e.Type == TypeBool {
+		if b, ok := parseBool(value); ok {
+			return strconv.FormatBool(b)
+		}
+	}
+	return value
+}
+
+// Choices returns the fixed set of values the entry accepts: its @enum, or
+// true/false for booleans. It returns nil when any value may be typed.
+func (e Entry) Choices() []string {
+	switch {
+	case len(e.Enum) > 0:
+		return e.Enum
+	case e.EffectiveType() == TypeBool:
+		return []string{"true", "false"}
+	}
+	return nil
+}
+
+// Warnings reports problems that do not make value invalid but are worth
+// confirming, such as a port that is already taken on localhost. It probes
+// the machine, so only call it when someone can act on the answer.
+func (e Entry) Warnings(value string) []string {
+	warnings := make([]string, 0)
+	if e.EffectiveType() == TypePort {
+		if port, err := strconv.Atoi(value); err == nil && !portAvailable(port) {
+			warnings = append(warnings, fmt.Sprintf("port %d is already in use on localhost", port))
+		}
+	}
+	return warnings
+}
+
+var portAvailable = func(port int) bool {
+	ln, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	ln.Close()
+	return true
+}
+
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "true", "1", "yes", "y", "on":
+		return true, true
+	case "false", "0", "no", "n", "off":
+		return false, true
+	}
+	return false, false
+}
+
+func validateType(key, typ, value, got string) error {
+	switch typ {
+	case "", TypeString:
+		return nil
+	case TypeInt:
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("%s must be an integer%s", key, got)
+		}
+	case TypeNumber:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s must be a number%s", key, got)
+		}
+	case TypeBool:
+		if _, ok := parseBool(value); !ok {
+			return fmt.Errorf("%s must be a boolean (true/false, yes/no, on/off, 1/0)%s", key, got)
+		}
+	case TypePort:
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("%s must be a port number between 1 and 65535%s", key, got)
+		}
+	case TypeURL:
+		u, err := url.Parse(value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s must be an absolute URL%s", key, got)
+		}
+	case TypeEmail:
+		addr, err := mail.ParseAddress(value)
+		if err != nil || addr.Address != value {
+			return fmt.Errorf("%s must be an email address%s", key, got)
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("%s must be a duration such as 30s, 5m or 1h30m%s", key, got)
+		}
+	default:
+		return fmt.Errorf("%s has unknown type %q", key, typ)
+	}
+	return nil
+}
+
+// parseBound converts a value of a numeric or duration type to a float so
+// that bounds can be compared uniformly.
+func parseBound(typ, value string) (float64, error) {
+	switch typ {
+	case TypeInt, TypePort:
+		n, err := strconv.ParseInt(value, 10, 64)
+		return float64(n), err
+	case TypeDuration:
+		d, err := time.ParseDuration(value)
+		return float64(d), err
+	default:
+		return strconv.ParseFloat(value, 64)
+	}
+}
+
+func validateBounds(key, typ, value, min, max, got string) error {
+	if min == "" && max == "" {
+		return nil
+	}
+	v, err := parseBound(typ, value)
+	if err != nil {
+		return nil
+	}
+	if lo, err := parseBound(typ, min); min != "" && err == nil && v < lo {
+		return fmt.Errorf("%s must be at least %s%s", key, min, got)
+	}
+	if hi, err := parseBound(typ, max); max != "" && err == nil && v > hi {
+		return fmt.Errorf("%s must be at most %s%s", key, max, got)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEffectiveType(t *testing.T) {
+	tests := []struct {
+		entry Entry
+		want  string
+	}{
+		{Entry{Key: "PORT", Default: "3000"}, TypePort},
+		{Entry{Key: "DB_PORT"}, TypePort},
+		{Entry{Key: "API_URL", Default: "http://localhost"}, TypeURL},
+		{Entry{Key: "API_URL", Default: "/api"}, ""},
+		{Entry{Key: "ADMIN_EMAIL", Default: "ops@example.com"}, TypeEmail},
+		{Entry{Key: "CACHE_ENABLED", Default: "yes"}, ""},
+		{Entry{Key: "REQUEST_TIMEOUT", Default: "30s"}, TypeDuration},
+		{Entry{Key: "REQUEST_TIMEOUT", Default: "5000"}, ""},
+		{Entry{Key: "PORT", Type: TypeString, Default: "x"}, TypeString},
+		{Entry{Key: "NAME", Default: "demo"}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.EffectiveType(); got != tt.want {
+			t.Fatalf("EffectiveType(%s=%s) = %q, want %q", tt.entry.Key, tt.entry.Default, got, tt.want)
+		}
+	}
+}
+
+func TestTypedValidation(t *testing.T) {
+	entries, err := Parse(`# @type=int
+# @min=1
+# @max=16
+WORKERS=4
+# @type=url
+# @scheme=https
+WEBHOOK_URL=https://hooks.example.com
+# @type=duration
+# @max=1m
+POLL_INTERVAL=10s
+PORT=3000
+`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	tests := []struct {
+		entry   Entry
+		value   string
+		wantErr string
+	}{
+		{entries[0], "16", ""},
+		{entries[0], "0", `WORKERS must be at least 1, got "0"`},
+		{entries[0], "17", `WORKERS must be at most 16, got "17"`},
+		{entries[1], "http://hooks.example.com", `WEBHOOK_URL must use scheme https, got "http://hooks.example.com"`},
+		{entries[2], "90s", `POLL_INTERVAL must be at most 1m, got "90s"`},
+		{entries[2], "soon", `POLL_INTERVAL must be a duration such as 30s, 5m or 1h30m, got "soon"`},
+		{entries[3], "http", `PORT must be a port number between 1 and 65535, got "http"`},
+	}
+	for _, tt := range tests {
+		err := tt.entry.Validate(tt.value)
+		if tt.wantErr == "" && err != nil {
+			t.Fatalf("Validate(%q) error = %v", tt.value, err)
+		}
+		if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+			t.Fatalf("Validate(%q): expected %q, got %v", tt.value, tt.wantErr, err)
+		}
+	}
+}
+
+func TestSchemaConsistencyErrors(t *testing.T) {
+	for _, input := range []string{
+		"# @min=1\nNAME=x\n",
+		"# @type=int\n# @max=ten\nN=1\n",
+		"# @scheme=https\nNAME=x\n",
+	} {
+		if _, err := Parse(input); err == nil {
+			t.Fatalf("expected schema error for %q", input)
+		}
+	}
+}
+
+func TestNormalizeAndWarnings(t *testing.T) {
+	e := Entry{Key: "FEATURE_X", Type: TypeBool}
+	for in, want := range map[string]string{"yes": "true", "Off": "false", "1": "true", "maybe": "maybe"} {
+		if got := e.Normalize(in); got != want {
+			t.Fatalf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := (Entry{Key: "FEATURE_X_ENABLED", Default: "1"}).Normalize("1"); got != "1" {
+		t.Fatalf("Normalize without @type=bool = %q, want 1", got)
+	}
+
+	defer func(orig func(int) bool) { portAvailable = orig }(portAvailable)
+	portAvailable = func(port int) bool { return port != 3000 }
+	port := Entry{Key: "PORT"}
+	if w := port.Warnings("3000"); len(w) != 1 || !strings.Contains(w[0], "already in use") {
+		t.Fatalf("expected port warning, got %v", w)
+	}
+	if w := port.Warnings("3001"); len(w) != 0 {
+		t.Fatalf("unexpected warnings %v", w)
+	}
+}
+
+func TestChoices(t *testing.T) {
+	tests := []struct {
+		entry Entry
+		want  []string
+	}{
+		{Entry{Key: "NODE_ENV", Enum: []string{"development", "production"}}, []string{"development", "production"}},
+		{Entry{Key: "CACHE_ENABLED", Default: "on"}, nil},
+		{Entry{Key: "DEBUG", Type: TypeBool}, []string{"true", "false"}},
+		{Entry{Key: "NAME", Default: "demo"}, nil},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.Choices(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Fatalf("Choices(%s) = %v, want %v", tt.entry.Key, got, tt.want)
+		}
+	}
+}
@@ -3,24 +3,193 @@ package env
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
-func WriteFile(path string, values map[string]string) error {
-	keys := make([]string, 0, len(values))
-	for k := range values {
-		keys = append(keys, k)
+// backupTimeFormat is the timestamp suffix of backup files, as in
+// .env.bak.20261018T101500.
+const backupTimeFormat = "20060102T150405"
+
+// WriteDocument writes doc to path with values substituted, keeping the
+// comments and layout of the template.
+func WriteDocument(path string, doc *Document, values map[string]string) error {
+	return WriteAtomic(path, doc.Render(values))
+}
+
+// WriteAtomic writes data to a temporary file next to path and renames it
+// into place, so readers never observe a partially written file. An
+// existing file keeps its mode and a symlink keeps pointing at the file it
+// names, which is what gets replaced; new files are only readable by their
+// owner.
+func WriteAtomic(path string, data []byte) error {
+	return WriteAtomicMode(path, data, 0o600)
+}
+
+// WriteAtomicMode is WriteAtomic creating new files with mode perm, for
+// files such as .env.example that hold no secrets.
+func WriteAtomicMode(path string, data []byte, perm os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
 	}
-	sort.Strings(keys)
-	lines := make([]byte, 0, len(keys)*16)
-	for _, k := range keys {
-		if values[k] == "" {
-			return fmt.Errorf("empty value for key %s", k)
-		}
-		lines = append(lines, []byte(fmt.Sprintf("%s=%s\n", k, values[k]))...)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
 	}
-	if err := os.WriteFile(path, lines, 0o600); err != nil {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
 		return fmt.Errorf("write %s: %w", path, err)
 	}
 	return nil
 }
+
+// restoreSuffix marks the backups Restore makes of the file it replaces, as
+// in .env.bak.20261018T101500.restore.
+const restoreSuffix = "restore"
+
+// Backup is a timestamped copy of a dotenv file.
+type Backup struct {
+	Path string
+	Time time.Time
+	// Restore is set for the copy Restore made of the file it replaced.
+	Restore bool
+	// seq orders backups made within the same second.
+	seq int
+}
+
+// BackupFile copies path to path.bak.<timestamp> and returns the backup's
+// path, or "" when path does not exist.
+func BackupFile(path string, now time.Time) (string, error) {
+	return backupFile(path, now, "")
+}
+
+// backupFile is BackupFile with an optional suffix after the timestamp.
+// Later backups within the same second get a counter from 2, as the first
+// one is the unnumbered name: .env.bak.<timestamp>.2, .3 and so on.
+func backupFile(path string, now time.Time, suffix string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("back up %s: %w", path, err)
+	}
+	base := path + ".bak." + now.Format(backupTimeFormat)
+	if suffix != "" {
+		base += "." + suffix
+	}
+	backup := base
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(backup); os.IsNotExist(err) {
+			break
+		}
+		backup = fmt.Sprintf("%s.%d", base, i)
+	}
+	if err := WriteAtomic(backup, data); err != nil {
+		return "", fmt.Errorf("back up %s: %w", path, err)
+	}
+	return backup, nil
+}
+
+// ListBackups returns the backups of path, newest first.
+func ListBackups(path string) ([]Backup, error) {
+	prefix := path + ".bak."
+	matches, err := filepath.Glob(globEscape(prefix) + "*")
+	if err != nil {
+		return nil, fmt.Errorf("list backups of %s: %w", path, err)
+	}
+	backups := make([]Backup, 0, len(matches))
+	for _, m := range matches {
+		if b, ok := parseBackup(m, strings.TrimPrefix(m, prefix)); ok {
+			backups = append(backups, b)
+		}
+	}
+	sort.SliceStable(backups, func(i, j int) bool {
+		if !backups[i].Time.Equal(backups[j].Time) {
+			return backups[i].Time.After(backups[j].Time)
+		}
+		return backups[i].seq > backups[j].seq
+	})
+	return backups, nil
+}
+
+// parseBackup reads the name of a backup after ".bak.": a timestamp, then
+// optionally restoreSuffix and a counter.
+func parseBackup(path, name string) (Backup, bool) {
+	fields := strings.Split(name, ".")
+	t, err := time.ParseInLocation(backupTimeFormat, fields[0], time.Local)
+	if err != nil {
+		return Backup{}, false
+	}
+	b := Backup{Path: path, Time: t}
+	fields = fields[1:]
+	if len(fields) > 0 && fields[0] == restoreSuffix {
+		b.Restore = true
+		fields = fields[1:]
+	}
+	switch len(fields) {
+	case 0:
+	case 1:
+		if b.seq, err = strconv.Atoi(fields[0]); err != nil || b.seq < 1 {
+			return Backup{}, false
+		}
+	default:
+		return Backup{}, false
+	}
+	return b, true
+}
+
+// LatestBackup returns the backup a restore without arguments uses: the
+// newest one not made by Restore, so restoring twice does not swap back to
+// the file the first restore replaced.
+func LatestBackup(backups []Backup) (Backup, bool) {
+	for _, b := range backups {
+		if !b.Restore {
+			return b, true
+		}
+	}
+	return Backup{}, false
+}
+
+// Restore replaces path with the contents of backup. The file being
+// replaced is backed up first, marked as made by Restore, so a restore can
+// itself be undone by naming that backup.
+func Restore(path, backup string, now time.Time) (string, error) {
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		return "", fmt.Errorf("read backup: %w", err)
+	}
+	if _, err = parseDocument(string(data)); err != nil {
+		return "", fmt.Errorf("parse backup %s: %w", backup, err)
+	}
+	previous, err := backupFile(path, now, restoreSuffix)
+	if err != nil {
+		return "", err
+	}
+	if err = WriteAtomic(path, data); err != nil {
+		return "", err
+	}
+	return previous, nil
+}
+
+func globEscape(s string) string {
+	return strings.NewReplacer(`*`, `\*`, `?`, `\?`, `[`, `\[`).Replace(s)
+}
@@ -0,0 +1,178 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWriteDocumentAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := WriteDocument(path, &Document{}, map[string]string{"B": "2", "A": "1"}); err != nil {
+		t.Fatalf("WriteDocument() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "A=1\nB=2\n" {
+		t.Fatalf("unexpected content: %q", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Fatalf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if b, err := BackupFile(path, time.Now()); err != nil || b != "" {
+		t.Fatalf("BackupFile() of missing file = %q, %v", b, err)
+	}
+	t1 := time.Date(2026, 10, 18, 10, 15, 0, 0, time.Local)
+	if err := os.WriteFile(path, []byte("A=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	first, err := BackupFile(path, t1)
+	if err != nil {
+		t.Fatalf("BackupFile() error = %v", err)
+	}
+	if filepath.Base(first) != ".env.bak.20261018T101500" {
+		t.Fatalf("unexpected backup name %s", first)
+	}
+	if err = os.WriteFile(path, []byte("A=2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	second, err := BackupFile(path, t1)
+	if err != nil || filepath.Base(second) != ".env.bak.20261018T101500.2" {
+		t.Fatalf("expected a numbered backup for the same timestamp, got %s (%v)", second, err)
+	}
+
+	backups, err := ListBackups(path)
+	if err != nil || len(backups) != 2 || backups[0].Path != second {
+		t.Fatalf("unexpected backups %+v (%v)", backups, err)
+	}
+
+	undo, err := Restore(path, first, t1.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "A=1\n" {
+		t.Fatalf("unexpected restored content %q", data)
+	}
+	data, _ = os.ReadFile(undo)
+	if string(data) != "A=2\n" {
+		t.Fatalf("restore must back up the replaced file, got %q", data)
+	}
+}
+
+func TestListBackupsOrder(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".env")
+	for _, name := range []string{".env.bak.20261018T101500", ".env.bak.20261018T101500.2", ".env.bak.20261018T101500.10", ".env.bak.20261018T101500.restore", ".env.bak.20261017T090000", ".env.bak.notes"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("A=1\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups, err := ListBackups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".env.bak.20261018T101500.10", ".env.bak.20261018T101500.2", ".env.bak.20261018T101500", ".env.bak.20261018T101500.restore", ".env.bak.20261017T090000"}
+	if len(backups) != len(want) {
+		t.Fatalf("got %d backups, want %d: %+v", len(backups), len(want), backups)
+	}
+	for i, name := range want {
+		if filepath.Base(backups[i].Path) != name {
+			t.Fatalf("backups[%d] = %s, want %s", i, filepath.Base(backups[i].Path), name)
+		}
+	}
+}
+
+func TestRestoreTwiceKeepsLatestBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	t1 := time.Date(2026, 10, 18, 10, 15, 0, 0, time.Local)
+	if err := os.WriteFile(path, []byte("A=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := BackupFile(path, t1); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("A=2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for i, now := range []time.Time{t1.Add(time.Minute), t1.Add(2 * time.Minute)} {
+		backups, err := ListBackups(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		latest, ok := LatestBackup(backups)
+		if !ok {
+			t.Fatal("no backup to restore")
+		}
+		if _, err = Restore(path, latest.Path, now); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := os.ReadFile(path); string(data) != "A=1\n" {
+			t.Fatalf("restore %d: content = %q, want A=1", i+1, data)
+		}
+	}
+}
+
+func TestWriteAtomicKeepsModeAndSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "shared.env")
+	if err := os.WriteFile(target, []byte("A=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, ".env")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	if err := WriteAtomic(link, []byte("A=2\n")); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("symlink replaced by a regular file (%v)", err)
+	}
+	info, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "A=2\n" {
+		t.Fatalf("target content = %q", data)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o644 {
+		t.Fatalf("mode = %v, want 0644", info.Mode().Perm())
+	}
+}
+
+func TestWriteAtomicMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not enforced on windows")
+	}
+	dir := t.TempDir()
+	created := filepath.Join(dir, ExampleFile)
+	if err := WriteAtomicMode(created, []byte("A=\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	existing := filepath.Join(dir, "private.example")
+	if err := os.WriteFile(existing, []byte("A=\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteAtomicMode(existing, []byte("A=\nB=\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]os.FileMode{created: 0o644, existing: 0o600} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Fatalf("%s mode = %v, want %v", filepath.Base(path), info.Mode().Perm(), want)
+		}
+	}
+}
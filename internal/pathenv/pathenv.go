@@ -0,0 +1,78 @@
+// Package pathenv reads and edits the PATH of an environment list such as
+// os.Environ(), and looks commands up on it. Windows names the variable
+// Path and matches environment keys case-insensitively, so a PATH= prefix
+// check alone misses it there.
+package pathenv
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// foldKeys reports whether environment keys are case-insensitive.
+var foldKeys = runtime.GOOS == "windows"
+
+// key returns the variable name of kv, or "" for entries such as the
+// "=C:=C:\dir" drive records Windows keeps.
+func key(kv string) string {
+	k, _, ok := strings.Cut(kv, "=")
+	if !ok {
+		return ""
+	}
+	return k
+}
+
+func isPath(kv string) bool {
+	k := key(kv)
+	if foldKeys {
+		return strings.EqualFold(k, "PATH")
+	}
+	return k == "PATH"
+}
+
+// Get returns the PATH of env; the last entry wins, as with os/exec.
+func Get(env []string) string {
+	path := ""
+	for _, kv := range env {
+		if isPath(kv) {
+			path = kv[len(key(kv))+1:]
+		}
+	}
+	return path
+}
+
+// Prepend returns env with dir put first on PATH. Other PATH entries are
+// dropped, and the variable keeps the name env spells it with.
+func Prepend(env []string, dir string) []string {
+	out := make([]string, 0, len(env)+1)
+	name, path := "PATH", dir
+	for _, kv := range env {
+		if isPath(kv) {
+			name = key(kv)
+			path = dir + string(os.PathListSeparator) + kv[len(name)+1:]
+			continue
+		}
+		out = append(out, kv)
+	}
+	return append(out, name+"="+path)
+}
+
+// LookPath searches for file like exec.LookPath, but in the PATH of env
+// when env is not nil.
+func LookPath(file string, env []string) (string, error) {
+	if env == nil || strings.ContainsRune(file, filepath.Separator) || strings.ContainsRune(file, '/') {
+		return exec.LookPath(file)
+	}
+	for _, dir := range filepath.SplitList(Get(env)) {
+		if dir == "" {
+			dir = "."
+		}
+		if found, err := exec.LookPath(filepath.Join(dir, file)); err == nil {
+			return found, nil
+		}
+	}
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
@@ -0,0 +1,52 @@
+package pathenv
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPrepend(t *testing.T) {
+	env := Prepend([]string{"HOME=/home/me", "PATH=/usr/bin"}, "/opt/node/bin")
+	want := "HOME=/home/me PATH=/opt/node/bin" + string(os.PathListSeparator) + "/usr/bin"
+	if got := strings.Join(env, " "); got != want {
+		t.Fatalf("Prepend() = %q, want %q", got, want)
+	}
+	if got := Prepend([]string{"HOME=/home/me"}, "/opt/node/bin"); strings.Join(got, " ") != "HOME=/home/me PATH=/opt/node/bin" {
+		t.Fatalf("Prepend() without PATH = %q", got)
+	}
+}
+
+func TestWindowsPathKey(t *testing.T) {
+	defer func(fold bool) { foldKeys = fold }(foldKeys)
+	foldKeys = true
+	base := []string{`=C:=C:\work`, `Path=C:\Windows`, `USERPROFILE=C:\Users\me`}
+	if got := Get(base); got != `C:\Windows` {
+		t.Fatalf("Get() = %q", got)
+	}
+	env := Prepend(base, `C:\node`)
+	want := `=C:=C:\work USERPROFILE=C:\Users\me Path=C:\node` + string(os.PathListSeparator) + `C:\Windows`
+	if got := strings.Join(env, " "); got != want {
+		t.Fatalf("Prepend() = %q, want %q", got, want)
+	}
+}
+
+func TestLookPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script")
+	}
+	dir := t.TempDir()
+	tool := filepath.Join(dir, "fake-tool")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LookPath("fake-tool", []string{"PATH=" + dir})
+	if err != nil || got != tool {
+		t.Fatalf("LookPath() = %q, %v", got, err)
+	}
+	if _, err := LookPath("fake-tool", nil); err == nil {
+		t.Fatal("fake-tool must not be found on the current PATH")
+	}
+}
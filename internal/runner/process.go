@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"io"
 	"os/exec"
+	"sort"
+	"strings"
 	"sync"
+
+	"ilaunch/internal/pathenv"
 )
 
 type EventType int
@@ -24,13 +28,47 @@ type Event struct {
 	Err      error
 }
 
-type Runner struct{}
+// Runner starts processes and streams their output. Any of Secrets that
+// appears in an output line is replaced with Redacted before it is emitted.
+// When Env is set, processes run with it instead of the current
+// environment, and commands are looked up on its PATH.
+type Runner struct {
+	Secrets []string
+	Env     []string
+}
+
+// Redacted replaces secret values in emitted lines.
+const Redacted = "••••"
 
-func (Runner) Run(ctx context.Context, name string, args ...string) <-chan Event {
+// minSecretLen keeps very short values from masking unrelated output.
+const minSecretLen = 4
+
+func (r Runner) Redact(line string) string {
+	secrets := make([]string, 0, len(r.Secrets))
+	for _, s := range r.Secrets {
+		if len(s) >= minSecretLen {
+			secrets = append(secrets, s)
+		}
+	}
+	// Longer secrets first so a secret containing another is fully masked.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	for _, s := range secrets {
+		line = strings.ReplaceAll(line, s, Redacted)
+	}
+	return line
+}
+
+func (r Runner) Run(ctx context.Context, name string, args ...string) <-chan Event {
 	ch := make(chan Event)
 	go func() {
 		defer close(ch)
-		cmd := exec.CommandContext(ctx, name, args...)
+		path, err := pathenv.LookPath(name, r.Env)
+		if err != nil {
+			ch <- Event{Type: EventError, Err: fmt.Errorf("start process %s: %w", name, err)}
+			return
+		}
+		cmd := exec.CommandContext(ctx, path, args...)
+		cmd.Env = r.Env
 		stdout, err := cmd.StdoutPipe()
 		if err != nil {
 			ch <- Event{Type: EventError, Err: fmt.Errorf("open stdout pipe: %w", err)}
@@ -48,11 +86,11 @@ func (Runner) Run(ctx context.Context, name string, args ...string) <-chan Event
 
 		var wg sync.WaitGroup
 		wg.Add(2)
-		readPipe := func(r io.Reader) {
+		readPipe := func(pipe io.Reader) {
 			defer wg.Done()
-			s := bufio.NewScanner(r)
+			s := bufio.NewScanner(pipe)
 			for s.Scan() {
-				ch <- Event{Type: EventLine, Line: s.Text()}
+				ch <- Event{Type: EventLine, Line: r.Redact(s.Text())}
 			}
 			if scanErr := s.Err(); scanErr != nil {
 				ch <- Event{Type: EventError, Err: fmt.Errorf("read process output: %w", scanErr)}
@@ -2,6 +2,8 @@ package runner
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 )
@@ -35,3 +37,42 @@ func TestRunnerRun(t *testing.T) {
 		t.Fatalf("expected line and done events, got line=%v done=%v", seenLine, seenDone)
 	}
 }
+
+func TestRunnerRedactsSecrets(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	r := Runner{Secrets: []string{"hunter2", "s3cr3t-token", "ab"}}
+	for ev := range r.Run(context.Background(), "sh", "-c", "echo login hunter2 with s3cr3t-token ab") {
+		if ev.Type != EventLine {
+			continue
+		}
+		if ev.Line != "login •••• with •••• ab" {
+			t.Fatalf("unexpected redacted line: %q", ev.Line)
+		}
+	}
+}
+
+func TestRunnerEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh scripts")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"tool $GREETING\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "fake-tool"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r := Runner{Env: []string{"PATH=" + dir + string(os.PathListSeparator) + os.Getenv("PATH"), "GREETING=hi"}}
+	var lines []string
+	for ev := range r.Run(context.Background(), "fake-tool") {
+		switch ev.Type {
+		case EventLine:
+			lines = append(lines, ev.Line)
+		case EventError:
+			t.Fatalf("unexpected error: %v", ev.Err)
+		}
+	}
+	if len(lines) != 1 || lines[0] != "tool hi" {
+		t.Fatalf("lines = %q", lines)
+	}
+}
@@ -2,19 +2,40 @@ package system
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
-	"runtime"
-	"strconv"
+	"slices"
 	"strings"
+
+	"ilaunch/internal/pathenv"
+	"ilaunch/internal/system/semver"
 )
 
+// MinNodeMajor is the oldest supported node major when the project does not
+// declare its own requirement.
 const MinNodeMajor = 18
 
 type CheckResult struct {
 	NodePath    string
 	NodeVersion string
-	PackageMgr  string
+	// NodeConstraints are the requirements the project declares for node.
+	NodeConstraints   []NodeConstraint
+	PackageMgr        string
+	PackageMgrVersion string
+	// PackageMgrSource explains how PackageMgr was chosen.
+	PackageMgrSource string
+	PackageManager   PackageManager
+	// Workspaces are the workspace package patterns of a monorepo.
+	Workspaces []string
+	// Toolchain is the node selected through a version manager, or nil
+	// when node comes from PATH.
+	Toolchain *Toolchain
+	// Provision is set when the installed package manager is not the
+	// version pinned by package.json.
+	Provision *Provision
+	// Warnings are problems that do not stop the bootstrap.
+	Warnings []string
 }
 
 type Commander interface {
@@ -22,60 +43,215 @@ type Commander interface {
 	Output(ctx context.Context, name string, args ...string) ([]byte, error)
 }
 
-type ExecCommander struct{}
+// ExecCommander runs commands on the machine. When Env is set, commands are
+// looked up on its PATH and run with it, e.g. to use a node selected
+// through a version manager.
+type ExecCommander struct {
+	Env []string
+}
 
-func (ExecCommander) LookPath(file string) (string, error) {
-	return exec.LookPath(file)
+func (c ExecCommander) LookPath(file string) (string, error) {
+	return pathenv.LookPath(file, c.Env)
 }
 
-func (ExecCommander) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
+func (c ExecCommander) Output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	path, err := pathenv.LookPath(name, c.Env)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Env = c.Env
 	return cmd.Output()
 }
 
-func CheckEnvironment(ctx context.Context, commander Commander) (CheckResult, error) {
-	nodePath, err := commander.LookPath("node")
+// bootstrapChecks are the built-in checks CheckEnvironment requires to
+// pass, besides those declared by the project.
+var bootstrapChecks = []string{"node", "package-manager", "lockfiles", "native-build"}
+
+// CheckEnvironment verifies the toolchain needed to bootstrap the project
+// in dir by running bootstrapChecks and the project checks of
+// ProjectRegistry. The first failure is returned as an error along with
+// what the checks found, so the caller can switch node or provision the
+// package manager and retry. Warnings, and built-in checks that only run as
+// a dependency of a project check, end up in CheckResult.Warnings.
+func CheckEnvironment(ctx context.Context, commander Commander, dir string) (CheckResult, error) {
+	var p probe
+	r, err := p.registry(dir)
 	if err != nil {
-		return CheckResult{}, fmt.Errorf("check node binary: %w", err)
+		return CheckResult{}, err
+	}
+	required := append(slices.Clone(bootstrapChecks), extraCheckIDs(r)...)
+	if r, err = r.Select(required...); err != nil {
+		return CheckResult{}, err
 	}
+	for _, d := range r.Run(ctx, commander, dir).Checks {
+		switch {
+		case d.Status == StatusPass:
+		case d.Status == StatusFail && slices.Contains(required, d.Check):
+			if err == nil {
+				err = p.failure(d)
+			}
+		default:
+			p.result.Warnings = append(p.result.Warnings, d.String())
+		}
+	}
+	return p.result, err
+}
 
-	pkgMgr := ""
-	if _, err = commander.LookPath("pnpm"); err == nil {
-		pkgMgr = "pnpm"
-	} else if _, err = commander.LookPath("npm"); err == nil {
-		pkgMgr = "npm"
-	} else {
-		return CheckResult{}, fmt.Errorf("check package manager: neither pnpm nor npm found")
+// probe records what the node and package-manager checks find for
+// CheckEnvironment. The registry runs checks concurrently, so each check
+// writes only its own fields.
+type probe struct {
+	result CheckResult
+	// nodeErr and packageManagerErr explain a failure of the check with
+	// wrapped errors such as *NodeVersionError and *ProvisionError.
+	nodeErr           error
+	packageManagerErr error
+}
+
+// registry returns ProjectRegistry(dir) with the node and package-manager
+// checks reporting to p.
+func (p *probe) registry(dir string) (*Registry, error) {
+	project, err := ProjectRegistry(dir)
+	if err != nil {
+		return nil, err
 	}
+	r := &Registry{}
+	for _, c := range project.checks {
+		switch c.ID() {
+		case "node":
+			c = NewCheck(c.ID(), c.Severity(), c.DependsOn(), p.checkNode)
+		case "package-manager":
+			c = NewCheck(c.ID(), c.Severity(), c.DependsOn(), p.checkPackageManager)
+		}
+		r.checks = append(r.checks, c)
+	}
+	return r, nil
+}
+
+// failure returns the error CheckEnvironment reports for the failed
+// diagnosis d.
+func (p *probe) failure(d Diagnosis) error {
+	switch {
+	case d.Check == "node" && p.nodeErr != nil:
+		return p.nodeErr
+	case d.Check == "package-manager" && p.packageManagerErr != nil:
+		return p.packageManagerErr
+	}
+	return fmt.Errorf("check %s", d)
+}
 
-	args := []string{"--version"}
-	if runtime.GOOS == "windows" {
-		args = []string{"-v"}
+func (p *probe) checkNode(ctx context.Context, commander Commander, dir string) Diagnosis {
+	constraints, err := NodeConstraints(dir)
+	if err != nil {
+		p.nodeErr = fmt.Errorf("read node version requirement: %w", err)
+		return fail(err.Error(), "fix the node version in the file named above")
+	}
+	p.result.NodeConstraints = constraints
+	want := []string{defaultNodeConstraint.String()}
+	if len(constraints) > 0 {
+		want = want[:0]
+		for _, c := range constraints {
+			want = append(want, c.String())
+		}
 	}
-	out, err := commander.Output(ctx, "node", args...)
+	path, err := commander.LookPath("node")
 	if err != nil {
-		return CheckResult{}, fmt.Errorf("read node version: %w", err)
+		p.nodeErr = fmt.Errorf("check node binary: %w", err)
+		return fail("node not found in PATH", "install Node.js "+strings.Join(want, ", "))
 	}
-	version := strings.TrimSpace(string(out))
-	if err = validateNodeVersion(version); err != nil {
-		return CheckResult{}, fmt.Errorf("validate node version: %w", err)
+	p.result.NodePath = path
+	v, err := ToolVersion(ctx, commander, "node", "--version")
+	if err != nil {
+		p.nodeErr = err
+		return fail(err.Error(), "reinstall Node.js")
 	}
+	p.result.NodeVersion = v.String()
+	if err = CheckNodeVersion(v.String(), constraints); err != nil {
+		p.nodeErr = fmt.Errorf("validate node version: %w", err)
+		return fail(err.Error(), "switch node with nvm, fnm, volta, asdf or mise, or run ilaunch --non-interactive --use-version-manager")
+	}
+	return pass(fmt.Sprintf("node %s satisfies %s", v, strings.Join(want, ", ")))
+}
 
-	return CheckResult{NodePath: nodePath, NodeVersion: version, PackageMgr: pkgMgr}, nil
+func (p *probe) checkPackageManager(ctx context.Context, commander Commander, dir string) Diagnosis {
+	spec, err := DetectPackageManager(dir, commander)
+	p.result.PackageMgr, p.result.PackageMgrSource = spec.Name, spec.Source
+	if err != nil {
+		p.packageManagerErr = fmt.Errorf("check package manager: %w", err)
+		var perr *ProvisionError
+		if errors.As(err, &perr) {
+			// Report what is known so the caller can provision and retry.
+			p.result.Provision = &perr.Provision
+			return fail(err.Error(), provisionHint(perr.Provision))
+		}
+		return fail(err.Error(), "install npm, pnpm, yarn or bun")
+	}
+	v, err := ToolVersion(ctx, commander, spec.Name, "--version")
+	if err != nil {
+		p.packageManagerErr = err
+		return fail(err.Error(), "reinstall "+spec.Name)
+	}
+	p.result.PackageMgrVersion = v.String()
+	if err = checkEngine(dir, spec.Name, v); err != nil {
+		p.packageManagerErr = fmt.Errorf("validate %s version: %w", spec.Name, err)
+		return fail(err.Error(), fmt.Sprintf("install a %s version that satisfies package.json engines.%s", spec.Name, spec.Name))
+	}
+	manager, err := NewPackageManager(spec.Name, v)
+	if err != nil {
+		p.packageManagerErr = err
+		return fail(err.Error(), "")
+	}
+	workspaces, err := manager.Workspaces(dir)
+	if err != nil {
+		p.packageManagerErr = fmt.Errorf("read %s workspaces: %w", spec.Name, err)
+		return fail(p.packageManagerErr.Error(), "fix the workspaces of the project")
+	}
+	p.result.PackageManager, p.result.Workspaces = manager, workspaces
+	if spec.Version != "" && spec.Version != v.String() {
+		provision := Provision{Name: spec.Name, Version: spec.Version, Installed: v.String()}
+		p.result.Provision = &provision
+		return warn(provision.String(), provisionHint(provision))
+	}
+	return pass(fmt.Sprintf("%s %s (from %s)", spec.Name, v, spec.Source))
 }
 
-func validateNodeVersion(version string) error {
-	trimmed := strings.TrimPrefix(version, "v")
-	parts := strings.Split(trimmed, ".")
-	if len(parts) == 0 {
-		return fmt.Errorf("invalid node version: %s", version)
+// ToolVersion runs name with args and extracts the version it prints, such
+// as 2.43.0 from "git version 2.43.0".
+func ToolVersion(ctx context.Context, commander Commander, name string, args ...string) (semver.Version, error) {
+	out, err := commander.Output(ctx, name, args...)
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("read %s version: %w", name, err)
+	}
+	v, err := semver.Coerce(string(out))
+	if err != nil {
+		return semver.Version{}, fmt.Errorf("read %s version: %w", name, err)
 	}
-	major, err := strconv.Atoi(parts[0])
+	return v, nil
+}
+
+// CheckVersion reports whether version satisfies the range spec, naming
+// source in the error so users know where the requirement is declared.
+func CheckVersion(tool string, version semver.Version, spec, source string) error {
+	rng, err := semver.ParseRange(spec)
 	if err != nil {
-		return fmt.Errorf("parse major version: %w", err)
+		return fmt.Errorf("%s: %w", source, err)
 	}
-	if major < MinNodeMajor {
-		return fmt.Errorf("node version %s is lower than required %d", version, MinNodeMajor)
+	if !rng.Contains(version) {
+		return fmt.Errorf("%s %s does not satisfy %s (from %s)", tool, version, spec, source)
 	}
 	return nil
 }
+
+// checkEngine enforces package.json engines.<tool> when it is declared.
+func checkEngine(dir, tool string, version semver.Version) error {
+	pkg, _, err := readPackageJSON(dir)
+	if err != nil {
+		return err
+	}
+	spec := strings.TrimSpace(pkg.Engines[tool])
+	if spec == "" {
+		return nil
+	}
+	return CheckVersion(tool, version, spec, "package.json engines."+tool)
+}
@@ -3,13 +3,19 @@ package system
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
 type fakeCommander struct {
 	paths map[string]string
 	out   []byte
-	err   error
+	// outputs overrides out for specific commands, keyed by the full
+	// command line or by the command name.
+	outputs map[string][]byte
+	err     error
 }
 
 func (f fakeCommander) LookPath(file string) (string, error) {
@@ -23,6 +29,12 @@ func (f fakeCommander) Output(ctx context.Context, name string, args ...string)
 	if f.err != nil {
 		return nil, f.err
 	}
+	if out, ok := f.outputs[strings.Join(append([]string{name}, args...), " ")]; ok {
+		return out, nil
+	}
+	if out, ok := f.outputs[name]; ok {
+		return out, nil
+	}
 	return f.out, nil
 }
 
@@ -30,7 +42,7 @@ func TestCheckEnvironment(t *testing.T) {
 	res, err := CheckEnvironment(context.Background(), fakeCommander{
 		paths: map[string]string{"node": "/usr/bin/node", "npm": "/usr/bin/npm"},
 		out:   []byte("v18.16.0\n"),
-	})
+	}, t.TempDir())
 	if err != nil {
 		t.Fatalf("CheckEnvironment() error = %v", err)
 	}
@@ -43,8 +55,58 @@ func TestCheckEnvironmentVersionTooLow(t *testing.T) {
 	_, err := CheckEnvironment(context.Background(), fakeCommander{
 		paths: map[string]string{"node": "/usr/bin/node", "pnpm": "/usr/bin/pnpm"},
 		out:   []byte("v16.0.0\n"),
-	})
+	}, t.TempDir())
 	if err == nil {
 		t.Fatal("expected version error")
 	}
 }
+
+func TestCheckEnvironmentProjectConstraint(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".nvmrc"), []byte("lts/iron\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := CheckEnvironment(context.Background(), fakeCommander{
+		paths: map[string]string{"node": "/usr/bin/node", "npm": "/usr/bin/npm"},
+		out:   []byte("v18.19.0\n"),
+	}, dir)
+	if err == nil || !strings.Contains(err.Error(), "from .nvmrc") {
+		t.Fatalf("expected error naming .nvmrc, got %v", err)
+	}
+}
+
+func TestCheckEnvironmentPackageManagerEngine(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"package.json": `{"engines": {"pnpm": ">=9.1 <10 || ^10.2"}}`})
+	cmd := fakeCommander{
+		paths:   map[string]string{"node": "/usr/bin/node", "pnpm": "/usr/bin/pnpm"},
+		out:     []byte("v20.11.1\n"),
+		outputs: map[string][]byte{"pnpm": []byte("9.0.6\n")},
+	}
+	_, err := CheckEnvironment(context.Background(), cmd, dir)
+	if err == nil || !strings.Contains(err.Error(), "pnpm 9.0.6 does not satisfy") {
+		t.Fatalf("expected engines.pnpm error, got %v", err)
+	}
+	cmd.outputs["pnpm"] = []byte("10.2.1\n")
+	res, err := CheckEnvironment(context.Background(), cmd, dir)
+	if err != nil {
+		t.Fatalf("CheckEnvironment() error = %v", err)
+	}
+	if res.PackageMgrVersion != "10.2.1" {
+		t.Fatalf("PackageMgrVersion = %q", res.PackageMgrVersion)
+	}
+}
+
+func TestCheckEnvironmentPinnedVersionMismatch(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"package.json": `{"packageManager": "pnpm@9.12.0"}`})
+	res, err := CheckEnvironment(context.Background(), fakeCommander{
+		paths:   map[string]string{"node": "/usr/bin/node", "pnpm": "/usr/bin/pnpm"},
+		out:     []byte("v20.11.1\n"),
+		outputs: map[string][]byte{"pnpm": []byte("8.15.4\n")},
+	}, dir)
+	if err != nil {
+		t.Fatalf("CheckEnvironment() error = %v", err)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "pins pnpm@9.12.0 but pnpm 8.15.4 is installed") {
+		t.Fatalf("Warnings = %v", res.Warnings)
+	}
+}
@@ -0,0 +1,47 @@
+package system
+
+import (
+	"fmt"
+)
+
+// Provision is a package manager version pinned by the packageManager field
+// that differs from, or is missing on, the machine.
+type Provision struct {
+	Name    string
+	Version string
+	// Installed is the version found on PATH, or "" when there is none.
+	Installed string
+}
+
+func (p Provision) String() string {
+	if p.Installed == "" {
+		return fmt.Sprintf("package.json pins %s@%s but %s is not installed", p.Name, p.Version, p.Name)
+	}
+	return fmt.Sprintf("package.json pins %s@%s but %s %s is installed", p.Name, p.Version, p.Name, p.Installed)
+}
+
+// ProvisionError is returned by CheckEnvironment when the pinned package
+// manager is not installed at all, so nothing can run until it is
+// provisioned.
+type ProvisionError struct {
+	Provision Provision
+}
+
+func (e *ProvisionError) Error() string {
+	return e.Provision.String()
+}
+
+// ProvisionCommands returns the corepack commands that install and activate
+// the pinned version. Corepack ships with Node.js but does not manage Bun.
+func ProvisionCommands(commander Commander, p Provision) ([][]string, error) {
+	if p.Name == "bun" {
+		return nil, fmt.Errorf("corepack cannot provision bun; install bun %s manually", p.Version)
+	}
+	if _, err := commander.LookPath("corepack"); err != nil {
+		return nil, fmt.Errorf("corepack not found in PATH; install %s@%s manually or upgrade Node.js", p.Name, p.Version)
+	}
+	return [][]string{
+		{"corepack", "enable", p.Name},
+		{"corepack", "prepare", p.Name + "@" + p.Version, "--activate"},
+	}, nil
+}
@@ -0,0 +1,46 @@
+package system
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestProvisionCommands(t *testing.T) {
+	p := Provision{Name: "pnpm", Version: "9.12.0", Installed: "8.15.4"}
+	cmds, err := ProvisionCommands(fakeCommander{paths: map[string]string{"corepack": "/usr/bin/corepack"}}, p)
+	if err != nil {
+		t.Fatalf("ProvisionCommands() error = %v", err)
+	}
+	got := make([]string, 0, len(cmds))
+	for _, c := range cmds {
+		got = append(got, strings.Join(c, " "))
+	}
+	want := "corepack enable pnpm; corepack prepare pnpm@9.12.0 --activate"
+	if strings.Join(got, "; ") != want {
+		t.Fatalf("commands = %q, want %q", strings.Join(got, "; "), want)
+	}
+
+	if _, err = ProvisionCommands(fakeCommander{}, p); err == nil || !strings.Contains(err.Error(), "corepack not found") {
+		t.Fatalf("expected missing corepack error, got %v", err)
+	}
+	if _, err = ProvisionCommands(fakeCommander{paths: map[string]string{"corepack": "/usr/bin/corepack"}}, Provision{Name: "bun", Version: "1.1.0"}); err == nil {
+		t.Fatal("expected error for bun")
+	}
+}
+
+func TestCheckEnvironmentProvision(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"package.json": `{"packageManager": "yarn@4.1.0"}`})
+	res, err := CheckEnvironment(context.Background(), fakeCommander{
+		paths:   map[string]string{"node": "/usr/bin/node", "yarn": "/usr/bin/yarn"},
+		out:     []byte("v20.11.1\n"),
+		outputs: map[string][]byte{"yarn": []byte("1.22.22\n")},
+	}, dir)
+	if err != nil {
+		t.Fatalf("CheckEnvironment() error = %v", err)
+	}
+	want := Provision{Name: "yarn", Version: "4.1.0", Installed: "1.22.22"}
+	if res.Provision == nil || *res.Provision != want {
+		t.Fatalf("Provision = %+v, want %+v", res.Provision, want)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !windows
+
+package system
+
+import "errors"
+
+func freeSpace(string) (uint64, error) {
+	return 0, errors.ErrUnsupported
+}
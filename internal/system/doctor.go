@@ -0,0 +1,360 @@
+package system
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Status is the outcome of a diagnostic; higher values are worse.
+type Status int
+
+const (
+	StatusPass Status = iota
+	// StatusSkip is reported for a check whose dependency failed.
+	StatusSkip
+	StatusWarn
+	StatusFail
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusPass:
+		return "pass"
+	case StatusSkip:
+		return "skip"
+	case StatusWarn:
+		return "warn"
+	default:
+		return "fail"
+	}
+}
+
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Diagnosis is the result of one doctor check. Hint tells the user how to
+// fix a warning or failure.
+type Diagnosis struct {
+	Check  string `json:"check"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+// String formats d as "check: detail (hint)".
+func (d Diagnosis) String() string {
+	s := d.Check + ": " + d.Detail
+	if d.Hint != "" {
+		s += " (" + d.Hint + ")"
+	}
+	return s
+}
+
+// Report is the outcome of Doctor. Status is the worst status of Checks.
+type Report struct {
+	Status Status      `json:"status"`
+	Checks []Diagnosis `json:"checks"`
+}
+
+func pass(detail string) Diagnosis {
+	return Diagnosis{Status: StatusPass, Detail: detail}
+}
+
+func warn(detail, hint string) Diagnosis {
+	return Diagnosis{Status: StatusWarn, Detail: detail, Hint: hint}
+}
+
+func fail(detail, hint string) Diagnosis {
+	return Diagnosis{Status: StatusFail, Detail: detail, Hint: hint}
+}
+
+// BuiltinChecks returns the checks iLaunch ships with, in report order.
+func BuiltinChecks() []Check {
+	return []Check{
+		NewCheck("node", SeverityError, nil, diagnoseNode),
+		NewCheck("package-manager", SeverityError, nil, diagnosePackageManager),
+		NewCheck("lockfiles", SeverityWarning, nil, func(_ context.Context, _ Commander, dir string) Diagnosis {
+			return diagnoseLockfiles(dir)
+		}),
+		NewCheck("corepack", SeverityWarning, nil, diagnoseCorepack),
+		NewCheck("native-build", SeverityWarning, nil, func(ctx context.Context, commander Commander, dir string) Diagnosis {
+			return diagnoseNativeBuild(ctx, commander, dir, runtime.GOOS)
+		}),
+		NewCheck("git", SeverityError, nil, diagnoseGit),
+		NewCheck("git-config", SeverityWarning, []string{"git"}, diagnoseGitConfig),
+		NewCheck("disk-space", SeverityError, nil, func(_ context.Context, _ Commander, dir string) Diagnosis {
+			return diagnoseDiskSpace(dir)
+		}),
+		NewCheck("write-access", SeverityError, nil, func(_ context.Context, _ Commander, dir string) Diagnosis {
+			return diagnoseWriteAccess(dir)
+		}),
+		NewCheck("npmrc", SeverityError, nil, func(_ context.Context, _ Commander, dir string) Diagnosis {
+			return diagnoseNpmrc(npmrcFiles(dir), os.Getenv)
+		}),
+	}
+}
+
+// Doctor runs the built-in checks and those of the project in dir. Unlike
+// CheckEnvironment it does not stop at the first problem.
+func Doctor(ctx context.Context, commander Commander, dir string) (Report, error) {
+	r, err := ProjectRegistry(dir)
+	if err != nil {
+		return Report{}, err
+	}
+	return r.Run(ctx, commander, dir), nil
+}
+
+func diagnoseNode(ctx context.Context, commander Commander, dir string) Diagnosis {
+	return new(probe).checkNode(ctx, commander, dir)
+}
+
+func diagnosePackageManager(ctx context.Context, commander Commander, dir string) Diagnosis {
+	return new(probe).checkPackageManager(ctx, commander, dir)
+}
+
+func provisionHint(p Provision) string {
+	if p.Name == "bun" {
+		return fmt.Sprintf("install bun %s from https://bun.sh", p.Version)
+	}
+	return fmt.Sprintf("run ilaunch --non-interactive --corepack, or corepack prepare %s@%s --activate", p.Name, p.Version)
+}
+
+// diagnoseLockfiles warns about lockfiles of different package managers,
+// which make installs depend on the tool that happens to run them.
+func diagnoseLockfiles(dir string) Diagnosis {
+	locks, err := findLockfiles(dir)
+	if err != nil {
+		return fail(err.Error(), "")
+	}
+	if len(locks) == 0 {
+		return pass("no lockfile yet; install creates one")
+	}
+	files := make([]string, 0, len(locks))
+	managers := make([]string, 0, len(locks))
+	for _, l := range locks {
+		files = append(files, fmt.Sprintf("%s (%s)", l.file, l.manager))
+		if !slices.Contains(managers, l.manager) {
+			managers = append(managers, l.manager)
+		}
+	}
+	keep := managers[0]
+	if pkg, _, err := readPackageJSON(dir); err == nil && pkg.PackageManager != "" {
+		if name, _, err := parsePackageManagerField(pkg.PackageManager); err == nil {
+			keep = name
+		}
+	}
+	switch {
+	case len(managers) == 1 && managers[0] == keep:
+		return pass(strings.Join(files, ", "))
+	case len(managers) == 1:
+		return warn(fmt.Sprintf("%s found, but package.json pins %s", files[0], keep),
+			fmt.Sprintf("delete it and run %s install to create the %s lockfile", keep, keep))
+	}
+	return warn("conflicting lockfiles: "+strings.Join(files, ", "),
+		fmt.Sprintf("keep the %s lockfile and delete the others", keep))
+}
+
+func diagnoseCorepack(ctx context.Context, commander Commander, dir string) Diagnosis {
+	if _, err := commander.LookPath("corepack"); err != nil {
+		hint := "upgrade to a Node.js release that bundles corepack, or npm install -g corepack"
+		if pkg, _, err := readPackageJSON(dir); err == nil && pkg.PackageManager != "" {
+			return warn("corepack not found; it provisions "+pkg.PackageManager+" pinned in package.json", hint)
+		}
+		return warn("corepack not found", hint)
+	}
+	v, err := ToolVersion(ctx, commander, "corepack", "--version")
+	if err != nil {
+		return warn(err.Error(), "reinstall corepack with npm install -g corepack")
+	}
+	return pass("corepack " + v.String())
+}
+
+func diagnoseGit(ctx context.Context, commander Commander, _ string) Diagnosis {
+	if _, err := commander.LookPath("git"); err != nil {
+		return fail("git not found in PATH", "install git from https://git-scm.com/downloads")
+	}
+	v, err := ToolVersion(ctx, commander, "git", "--version")
+	if err != nil {
+		return fail(err.Error(), "reinstall git")
+	}
+	return pass("git " + v.String())
+}
+
+// diagnoseGitConfig checks the identity git needs for the initial commit.
+func diagnoseGitConfig(ctx context.Context, commander Commander, _ string) Diagnosis {
+	values := make([]string, 0, 2)
+	missing := make([]string, 0, 2)
+	hints := make([]string, 0, 2)
+	for _, kv := range [][2]string{{"user.name", "Your Name"}, {"user.email", "you@example.com"}} {
+		out, err := commander.Output(ctx, "git", "config", "--get", kv[0])
+		value := strings.TrimSpace(string(out))
+		if err != nil || value == "" {
+			missing = append(missing, kv[0])
+			hints = append(hints, fmt.Sprintf("git config --global %s %q", kv[0], kv[1]))
+			continue
+		}
+		values = append(values, value)
+	}
+	if len(missing) > 0 {
+		return warn(strings.Join(missing, " and ")+" not set; the initial commit will fail", "run "+strings.Join(hints, " and "))
+	}
+	return pass(fmt.Sprintf("%s <%s>", values[0], values[1]))
+}
+
+// Free space below which installs are likely to fail or to fill the disk.
+const (
+	minFreeSpace  = 512 << 20
+	lowFreeSpace  = 2 << 30
+	freeSpaceHint = "free up disk space; node_modules often takes several hundred MB"
+)
+
+func diagnoseDiskSpace(dir string) Diagnosis {
+	free, err := freeSpace(dir)
+	if err != nil {
+		return warn("cannot determine free space: "+err.Error(), "")
+	}
+	detail := formatBytes(free) + " free"
+	switch {
+	case free < minFreeSpace:
+		return fail(detail, freeSpaceHint)
+	case free < lowFreeSpace:
+		return warn(detail, freeSpaceHint)
+	}
+	return pass(detail)
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1 << 10
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// diagnoseWriteAccess creates and removes a file in dir, which is what .env
+// creation and installs need.
+func diagnoseWriteAccess(dir string) Diagnosis {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	f, err := os.CreateTemp(dir, ".ilaunch-doctor-*")
+	if err != nil {
+		return fail(fmt.Sprintf("cannot write to %s: %v", abs, err), "fix the directory permissions or run ilaunch in a writable clone")
+	}
+	f.Close()
+	if err = os.Remove(f.Name()); err != nil {
+		return warn(fmt.Sprintf("cannot remove %s: %v", f.Name(), err), "delete the file by hand")
+	}
+	return pass(abs + " is writable")
+}
+
+// npmrcFiles returns the project and user .npmrc paths, project first.
+func npmrcFiles(dir string) []string {
+	files := []string{filepath.Join(dir, ".npmrc")}
+	if user := os.Getenv("NPM_CONFIG_USERCONFIG"); user != "" {
+		return append(files, user)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files, filepath.Join(home, ".npmrc"))
+	}
+	return files
+}
+
+// defaultRegistry is used by npm when no .npmrc sets registry.
+const defaultRegistry = "https://registry.npmjs.org/"
+
+var npmrcEnvRef = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// diagnoseNpmrc checks the registry settings of the .npmrc files without
+// contacting the registries. files are in precedence order; missing files
+// are skipped. Only the first file may hold literal tokens, as it is the
+// one committed with the project.
+func diagnoseNpmrc(files []string, getenv func(string) string) Diagnosis {
+	registry := ""
+	warnings := make([]string, 0)
+	failures := make([]string, 0)
+	hints := make([]string, 0)
+	read := 0
+	for i, path := range files {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("read %s: %v", path, err))
+			continue
+		}
+		read++
+		name := filepath.Base(path)
+		if i > 0 {
+			name = path
+		}
+		sc := bufio.NewScanner(strings.NewReader(string(data)))
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+				continue
+			}
+			key, value, _ := strings.Cut(line, "=")
+			key, value = strings.TrimSpace(key), strings.Trim(strings.TrimSpace(value), `"'`)
+			for _, ref := range npmrcEnvRef.FindAllStringSubmatch(value, -1) {
+				if getenv(ref[1]) == "" {
+					failures = append(failures, fmt.Sprintf("%s: %s uses ${%s}, which is not set", name, key, ref[1]))
+					hints = append(hints, "export "+ref[1]+" before installing")
+				}
+			}
+			switch {
+			case key == "registry" || strings.HasSuffix(key, ":registry"):
+				u, err := url.Parse(value)
+				if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+					failures = append(failures, fmt.Sprintf("%s: %s %q is not an http(s) URL", name, key, value))
+					hints = append(hints, "set "+key+" to the registry URL, e.g. "+defaultRegistry)
+					continue
+				}
+				if u.Scheme == "http" {
+					warnings = append(warnings, fmt.Sprintf("%s: %s %s is not encrypted", name, key, value))
+					hints = append(hints, "use an https registry URL")
+				}
+				if key == "registry" && registry == "" {
+					registry = value
+				}
+			case key == "strict-ssl" && value == "false":
+				warnings = append(warnings, name+": strict-ssl=false disables certificate checks")
+				hints = append(hints, "remove strict-ssl=false and set cafile for a private CA")
+			case i == 0 && (strings.HasSuffix(key, "_authToken") || strings.HasSuffix(key, "_auth") || strings.HasSuffix(key, "_password")) &&
+				!npmrcEnvRef.MatchString(value):
+				warnings = append(warnings, fmt.Sprintf("%s: %s is a literal credential in the project", name, key))
+				hints = append(hints, "replace it with ${NPM_TOKEN} and keep the token out of git")
+			}
+		}
+	}
+	if registry == "" {
+		registry = defaultRegistry + " (default)"
+	}
+	hint := strings.Join(slices.Compact(hints), "; ")
+	switch {
+	case len(failures) > 0:
+		return fail(strings.Join(append(failures, warnings...), "; "), hint)
+	case len(warnings) > 0:
+		return warn(strings.Join(warnings, "; "), hint)
+	case read == 0:
+		return pass("no .npmrc; registry " + registry)
+	}
+	return pass("registry " + registry)
+}
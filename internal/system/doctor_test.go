@@ -0,0 +1,191 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctorRunsEveryCheck(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".nvmrc":            "20\n",
+		"package-lock.json": "{}",
+		"yarn.lock":         "",
+	})
+	report, err := Doctor(context.Background(), fakeCommander{
+		paths: map[string]string{"node": "/bin/node", "npm": "/bin/npm"},
+		outputs: map[string][]byte{
+			"node": []byte("v18.19.0\n"),
+			"npm":  []byte("10.2.4\n"),
+		},
+	}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Checks) != len(BuiltinChecks()) {
+		t.Fatalf("got %d checks, want %d", len(report.Checks), len(BuiltinChecks()))
+	}
+	want := map[string]Status{
+		"node":            StatusFail,
+		"package-manager": StatusPass,
+		"lockfiles":       StatusWarn,
+		"corepack":        StatusWarn,
+		"git":             StatusFail,
+		"git-config":      StatusSkip,
+		"write-access":    StatusPass,
+	}
+	for _, c := range report.Checks {
+		if status, ok := want[c.Check]; ok && c.Status != status {
+			t.Errorf("%s: status = %s (%s), want %s", c.Check, c.Status, c.Detail, status)
+		}
+		if c.Status > StatusSkip && c.Hint == "" && c.Check != "disk-space" {
+			t.Errorf("%s: %s without a hint", c.Check, c.Status)
+		}
+	}
+	if report.Status != StatusFail {
+		t.Fatalf("report status = %s, want fail", report.Status)
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"status":"fail"`) || !strings.Contains(string(data), `"check":"node"`) {
+		t.Fatalf("unexpected JSON: %s", data)
+	}
+}
+
+func TestDiagnoseGitConfig(t *testing.T) {
+	git := map[string]string{"git": "/bin/git"}
+	tests := []struct {
+		name      string
+		commander fakeCommander
+		want      Status
+		detail    string
+	}{
+		{
+			name: "identity set",
+			commander: fakeCommander{paths: git, outputs: map[string][]byte{
+				"git config --get user.name":  []byte("Ada Lovelace\n"),
+				"git config --get user.email": []byte("ada@example.com\n"),
+			}},
+			want:   StatusPass,
+			detail: "Ada Lovelace <ada@example.com>",
+		},
+		{
+			name: "email missing",
+			commander: fakeCommander{paths: git, outputs: map[string][]byte{
+				"git config --get user.name": []byte("Ada Lovelace\n"),
+			}},
+			want:   StatusWarn,
+			detail: "user.email not set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diagnoseGitConfig(context.Background(), tt.commander, "")
+			if got.Status != tt.want || !strings.Contains(got.Detail, tt.detail) {
+				t.Fatalf("got %s %q, want %s containing %q", got.Status, got.Detail, tt.want, tt.detail)
+			}
+		})
+	}
+}
+
+func TestDiagnoseLockfiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		want   Status
+		detail string
+		hint   string
+	}{
+		{name: "none", files: map[string]string{}, want: StatusPass, detail: "no lockfile"},
+		{name: "bun text and binary", files: map[string]string{"bun.lock": "", "bun.lockb": ""}, want: StatusPass, detail: "bun.lockb (bun), bun.lock (bun)"},
+		{
+			name:   "lockfile of another manager",
+			files:  map[string]string{"package.json": `{"packageManager": "pnpm@9.1.0"}`, "yarn.lock": ""},
+			want:   StatusWarn,
+			detail: "yarn.lock (yarn) found, but package.json pins pnpm",
+			hint:   "pnpm install",
+		},
+		{
+			name:   "conflict follows packageManager",
+			files:  map[string]string{"package.json": `{"packageManager": "yarn@4.1.0"}`, "package-lock.json": "{}", "yarn.lock": ""},
+			want:   StatusWarn,
+			detail: "package-lock.json (npm), yarn.lock (yarn)",
+			hint:   "keep the yarn lockfile",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diagnoseLockfiles(writeFiles(t, tt.files))
+			if got.Status != tt.want || !strings.Contains(got.Detail, tt.detail) || !strings.Contains(got.Hint, tt.hint) {
+				t.Fatalf("got %s %q (hint %q)", got.Status, got.Detail, got.Hint)
+			}
+		})
+	}
+}
+
+func TestDiagnoseNpmrc(t *testing.T) {
+	env := map[string]string{"NPM_TOKEN": "secret"}
+	tests := []struct {
+		name    string
+		project string
+		user    string
+		want    Status
+		detail  string
+	}{
+		{name: "no files", want: StatusPass, detail: "no .npmrc; registry https://registry.npmjs.org/ (default)"},
+		{
+			name:    "project registry wins",
+			project: "registry=https://npm.example.com/\n//npm.example.com/:_authToken=${NPM_TOKEN}\n",
+			user:    "registry=https://other.example.com/\n",
+			want:    StatusPass,
+			detail:  "registry https://npm.example.com/",
+		},
+		{name: "unset variable", project: "//npm.example.com/:_authToken=${CI_TOKEN}\n", want: StatusFail, detail: "${CI_TOKEN}, which is not set"},
+		{name: "invalid registry", project: "@acme:registry=npm.example.com\n", want: StatusFail, detail: "is not an http(s) URL"},
+		{name: "plain http", project: "registry=http://npm.example.com/\n", want: StatusWarn, detail: "is not encrypted"},
+		{name: "strict ssl off", user: "; corporate proxy\nstrict-ssl=false\n", want: StatusWarn, detail: "strict-ssl=false"},
+		{name: "committed token", project: "//registry.npmjs.org/:_authToken=npm_abc123\n", want: StatusWarn, detail: "literal credential"},
+		{name: "user token", user: "//registry.npmjs.org/:_authToken=npm_abc123\n", want: StatusPass},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := []string{filepath.Join(dir, "project.npmrc"), filepath.Join(dir, "user.npmrc")}
+			for i, content := range []string{tt.project, tt.user} {
+				if content == "" {
+					continue
+				}
+				if err := os.WriteFile(files[i], []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := diagnoseNpmrc(files, func(k string) string { return env[k] })
+			if got.Status != tt.want || !strings.Contains(got.Detail, tt.detail) {
+				t.Fatalf("got %s %q, want %s containing %q", got.Status, got.Detail, tt.want, tt.detail)
+			}
+			if got.Status != StatusPass && got.Hint == "" {
+				t.Fatal("expected a hint")
+			}
+		})
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[uint64]string{
+		512:             "512 B",
+		1536:            "1.5 KiB",
+		3 << 30:         "3.0 GiB",
+		5<<40 + 512<<30: "5.5 TiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"ilaunch/internal/system/semver"
+)
+
+// nativePackages are popular packages that compile a native addon with
+// node-gyp when no prebuilt binary matches the platform or Node.js version.
+var nativePackages = []string{
+	"@serialport/bindings-cpp",
+	"@tensorflow/tfjs-node",
+	"argon2",
+	"bcrypt",
+	"better-sqlite3",
+	"bufferutil",
+	"canvas",
+	"cpu-features",
+	"deasync",
+	"isolated-vm",
+	"kerberos",
+	"leveldown",
+	"libxmljs",
+	"microtime",
+	"node-pty",
+	"node-sass",
+	"re2",
+	"sharp",
+	"sqlite3",
+	"utf-8-validate",
+	"zeromq",
+}
+
+// pythonRange is the Python supported by node-gyp.
+var pythonRange = semver.MustParseRange(">=3.6")
+
+// buildTool is a program node-gyp needs; any of names will do.
+type buildTool struct {
+	label string
+	names []string
+	// versions, when set, is checked against the --version output.
+	versions *semver.Range
+}
+
+// buildTools returns what node-gyp needs on goos. On Windows the compiler
+// comes from Visual Studio, which node-gyp locates itself.
+func buildTools(goos string) []buildTool {
+	if goos == "windows" {
+		return []buildTool{{"Python", []string{"python3", "python", "py"}, &pythonRange}}
+	}
+	return []buildTool{
+		{"python3", []string{"python3"}, &pythonRange},
+		{"make", []string{"make", "gmake"}, nil},
+		{"a C++ compiler", []string{"c++", "g++", "clang++"}, nil},
+	}
+}
+
+func (t buildTool) String() string {
+	if len(t.names) == 1 {
+		return t.label
+	}
+	last := len(t.names) - 1
+	return fmt.Sprintf("%s (%s or %s)", t.label, strings.Join(t.names[:last], ", "), t.names[last])
+}
+
+func buildToolsHint(goos string) string {
+	switch goos {
+	case "darwin":
+		return "install the Xcode Command Line Tools with xcode-select --install"
+	case "windows":
+		return "install Python 3 and Visual Studio Build Tools with the \"Desktop development with C++\" workload"
+	}
+	return "install python3, make and g++, e.g. apt install python3 make g++ (Debian/Ubuntu), dnf install python3 make gcc-c++ (Fedora) or apk add python3 make g++ (Alpine)"
+}
+
+// NativeDependencies returns the packages of the project in dir that usually
+// need a native build, found in the package.json dependencies or in the
+// lockfile, which also lists transitive dependencies. Bun's binary lockfile
+// is not read.
+func NativeDependencies(dir string) ([]string, error) {
+	pkg, _, err := readPackageJSON(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, deps := range []map[string]string{pkg.Dependencies, pkg.DevDependencies, pkg.OptionalDependencies} {
+		for name := range deps {
+			names[name] = true
+		}
+	}
+	locks, err := findLockfiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range locks {
+		path := filepath.Join(dir, l.file)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		if err = lockfilePackages(l.file, data, names); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	found := make([]string, 0)
+	for _, name := range nativePackages {
+		if names[name] {
+			found = append(found, name)
+		}
+	}
+	return found, nil
+}
+
+// lockfilePackages adds the package names listed in a lockfile to names.
+func lockfilePackages(file string, data []byte, names map[string]bool) error {
+	switch file {
+	case "package-lock.json", "npm-shrinkwrap.json":
+		return npmLockPackages(data, names)
+	case "yarn.lock":
+		yarnLockPackages(data, names)
+	case "pnpm-lock.yaml":
+		pnpmLockPackages(data, names)
+	case "bun.lock":
+		for _, m := range bunLockEntry.FindAllSubmatch(data, -1) {
+			names[string(m[1])] = true
+		}
+	}
+	return nil
+}
+
+// npmLockPackage is an entry of the lockfile v1 "dependencies" tree.
+type npmLockPackage struct {
+	Dependencies map[string]npmLockPackage `json:"dependencies"`
+}
+
+// npmLockPackages reads the "packages" map of lockfile v2 and v3, keyed by
+// install path, and the "dependencies" tree of v1.
+func npmLockPackages(data []byte, names map[string]bool) error {
+	var lock struct {
+		Packages     map[string]json.RawMessage `json:"packages"`
+		Dependencies map[string]npmLockPackage  `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return err
+	}
+	for path := range lock.Packages {
+		if i := strings.LastIndex(path, "node_modules/"); i >= 0 {
+			names[path[i+len("node_modules/"):]] = true
+		}
+	}
+	var walk func(deps map[string]npmLockPackage)
+	walk = func(deps map[string]npmLockPackage) {
+		for name, dep := range deps {
+			names[name] = true
+			walk(dep.Dependencies)
+		}
+	}
+	walk(lock.Dependencies)
+	return nil
+}
+
+// yarnLockPackages reads entry headers such as `bcrypt@^5.1.0:` (Yarn 1) or
+// `"sharp@npm:^0.33.0, sharp@npm:^0.33.2":` (Yarn 2+).
+func yarnLockPackages(data []byte, names map[string]bool) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || line[0] == ' ' || line[0] == '#' || !strings.HasSuffix(line, ":") {
+			continue
+		}
+		for spec := range strings.SplitSeq(strings.TrimSuffix(line, ":"), ",") {
+			if name := packageName(strings.Trim(strings.TrimSpace(spec), `"`)); name != "" {
+				names[name] = true
+			}
+		}
+	}
+}
+
+// pnpmLockPackages reads the keys of the "packages" section: `bcrypt@5.1.1`
+// in lockfile v9, `/bcrypt@5.1.1` in v6 and `/bcrypt/5.1.1` before that.
+func pnpmLockPackages(data []byte, names map[string]bool) {
+	inPackages := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Text()
+		if line == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if !strings.HasPrefix(line, " ") {
+			inPackages = line == "packages:"
+			continue
+		}
+		if !inPackages || strings.HasPrefix(line, "   ") {
+			continue
+		}
+		key := strings.Trim(strings.TrimSuffix(strings.TrimSpace(line), ":"), `'"`)
+		key = strings.TrimPrefix(key, "/")
+		if name := packageName(key); name != "" {
+			names[name] = true
+			continue
+		}
+		// Old layout: name/version, where the name may be scoped.
+		if i := strings.LastIndex(key, "/"); i > 0 {
+			names[key[:i]] = true
+		}
+	}
+}
+
+// bunLockEntry matches the "name@version" that starts each entry of the
+// "packages" map of bun.lock.
+var bunLockEntry = regexp.MustCompile(`(?m)^\s*"[^"]+":\s*\[\s*"((?:@[^@"/]+/)?[^@"]+)@`)
+
+// packageName returns the name part of a "name@range" specifier, or "" when
+// spec has no version part. The @ of a scope is not a separator.
+func packageName(spec string) string {
+	if len(spec) < 2 {
+		return ""
+	}
+	i := strings.Index(spec[1:], "@")
+	if i < 0 {
+		return ""
+	}
+	return spec[:i+1]
+}
+
+// diagnoseNativeBuild verifies the toolchain node-gyp needs when the
+// project has dependencies that usually compile native code. Most of them
+// download a prebuilt binary, so a missing tool is only a warning.
+func diagnoseNativeBuild(ctx context.Context, commander Commander, dir, goos string) Diagnosis {
+	deps, err := NativeDependencies(dir)
+	if err != nil {
+		return fail(err.Error(), "")
+	}
+	if len(deps) == 0 {
+		return pass("no dependencies need a native build")
+	}
+	found := make([]string, 0)
+	missing := make([]string, 0)
+	for _, tool := range buildTools(goos) {
+		i := slices.IndexFunc(tool.names, func(name string) bool {
+			_, err := commander.LookPath(name)
+			return err == nil
+		})
+		if i < 0 {
+			missing = append(missing, tool.String())
+			continue
+		}
+		name := tool.names[i]
+		if tool.versions == nil {
+			found = append(found, name)
+			continue
+		}
+		v, err := ToolVersion(ctx, commander, name, "--version")
+		if err != nil {
+			// Old releases print the version on stderr; accept what cannot be read.
+			found = append(found, name)
+			continue
+		}
+		if !tool.versions.Contains(v) {
+			missing = append(missing, fmt.Sprintf("%s %s (found %s)", tool.label, tool.versions, v))
+			continue
+		}
+		found = append(found, fmt.Sprintf("%s %s", name, v))
+	}
+	if len(missing) > 0 {
+		return warn(fmt.Sprintf("%s may compile native code with node-gyp, which needs %s", strings.Join(deps, ", "), strings.Join(missing, " and ")),
+			buildToolsHint(goos))
+	}
+	return pass(fmt.Sprintf("%s can build with %s", strings.Join(deps, ", "), strings.Join(found, ", ")))
+}
@@ -0,0 +1,182 @@
+package system
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNativeDependencies(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{name: "none", files: map[string]string{"package.json": `{"dependencies": {"bcryptjs": "^2.4.3"}}`}},
+		{
+			name:  "package.json",
+			files: map[string]string{"package.json": `{"dependencies": {"sharp": "^0.33.0"}, "devDependencies": {"better-sqlite3": "^11.0.0"}}`},
+			want:  []string{"better-sqlite3", "sharp"},
+		},
+		{
+			name: "npm lockfile v3 transitive",
+			files: map[string]string{"package-lock.json": `{"lockfileVersion": 3, "packages": {
+				"": {"name": "app"},
+				"node_modules/express": {"version": "4.19.2"},
+				"node_modules/@serialport/bindings-cpp": {"version": "12.0.1"},
+				"node_modules/ws/node_modules/bufferutil": {"version": "4.0.8"}}}`},
+			want: []string{"@serialport/bindings-cpp", "bufferutil"},
+		},
+		{
+			name:  "npm lockfile v1",
+			files: map[string]string{"package-lock.json": `{"lockfileVersion": 1, "dependencies": {"ws": {"version": "8.0.0", "dependencies": {"utf-8-validate": {"version": "6.0.3"}}}}}`},
+			want:  []string{"utf-8-validate"},
+		},
+		{
+			name: "yarn classic and berry",
+			files: map[string]string{"yarn.lock": `# yarn lockfile v1
+
+"@types/bcrypt@^5.0.0":
+  version "5.0.2"
+
+"argon2@npm:^0.40.1, argon2@npm:^0.40.3":
+  version: 0.40.3
+
+sqlite3@^5.1.7:
+  version "5.1.7"
+`},
+			want: []string{"argon2", "sqlite3"},
+		},
+		{
+			name: "pnpm lockfile v9 and v5",
+			files: map[string]string{"pnpm-lock.yaml": `lockfileVersion: '9.0'
+
+importers:
+  .:
+    dependencies:
+      canvas:
+        specifier: ^2.11.2
+
+packages:
+
+  '@tensorflow/tfjs-node@4.20.0':
+    resolution: {integrity: sha512-abc}
+
+  /re2/1.21.3:
+    resolution: {integrity: sha512-def}
+
+snapshots:
+
+  sharp@0.33.5: {}
+`},
+			want: []string{"@tensorflow/tfjs-node", "re2"},
+		},
+		{
+			name: "bun text lockfile",
+			files: map[string]string{"bun.lock": `{
+  "lockfileVersion": 1,
+  "workspaces": {
+    "": { "name": "app", "dependencies": { "zod": "^3.23.8" } },
+  },
+  "packages": {
+    "node-pty": ["node-pty@1.0.0", "", { "dependencies": { "nan": "^2.17.0" } }, "sha512-abc"],
+    "zod": ["zod@3.23.8", "", {}, "sha512-def"],
+  }
+}`},
+			want: []string{"node-pty"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NativeDependencies(writeFiles(t, tt.files))
+			if err != nil {
+				t.Fatalf("NativeDependencies() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("NativeDependencies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiagnoseNativeBuild(t *testing.T) {
+	native := map[string]string{"package.json": `{"dependencies": {"bcrypt": "^5.1.1"}}`}
+	unix := map[string]string{"python3": "/usr/bin/python3", "make": "/usr/bin/make", "g++": "/usr/bin/g++"}
+	tests := []struct {
+		name      string
+		files     map[string]string
+		goos      string
+		commander fakeCommander
+		want      Status
+		detail    string
+		hint      string
+	}{
+		{
+			name:   "no native dependencies",
+			files:  map[string]string{"package.json": `{"dependencies": {"express": "^4.19.2"}}`},
+			goos:   "linux",
+			want:   StatusPass,
+			detail: "no dependencies need a native build",
+		},
+		{
+			name:      "toolchain present",
+			files:     native,
+			goos:      "linux",
+			commander: fakeCommander{paths: unix, out: []byte("Python 3.12.3\n")},
+			want:      StatusPass,
+			detail:    "bcrypt can build with python3 3.12.3, make, g++",
+		},
+		{
+			name:      "compiler and python missing on linux",
+			files:     native,
+			goos:      "linux",
+			commander: fakeCommander{paths: map[string]string{"make": "/usr/bin/make"}},
+			want:      StatusWarn,
+			detail:    "bcrypt may compile native code with node-gyp, which needs python3 and a C++ compiler (c++, g++ or clang++)",
+			hint:      "apt install python3 make g++",
+		},
+		{
+			name:      "python too old",
+			files:     native,
+			goos:      "darwin",
+			commander: fakeCommander{paths: map[string]string{"python3": "/usr/bin/python3", "make": "/usr/bin/make", "clang++": "/usr/bin/clang++"}, out: []byte("Python 3.5.2\n")},
+			want:      StatusWarn,
+			detail:    "python3 >=3.6 (found 3.5.2)",
+			hint:      "xcode-select --install",
+		},
+		{
+			name:      "windows only needs python",
+			files:     native,
+			goos:      "windows",
+			commander: fakeCommander{paths: map[string]string{"py": `C:\Windows\py.exe`}, out: []byte("Python 3.11.9\n")},
+			want:      StatusPass,
+			detail:    "py 3.11.9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diagnoseNativeBuild(context.Background(), tt.commander, writeFiles(t, tt.files), tt.goos)
+			if got.Status != tt.want || !strings.Contains(got.Detail, tt.detail) || !strings.Contains(got.Hint, tt.hint) {
+				t.Fatalf("got %s %q (hint %q), want %s containing %q", got.Status, got.Detail, got.Hint, tt.want, tt.detail)
+			}
+		})
+	}
+}
+
+func TestCheckEnvironmentNativeToolchain(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"package.json": `{"dependencies": {"sharp": "^0.33.5"}}`})
+	res, err := CheckEnvironment(context.Background(), fakeCommander{
+		paths: map[string]string{"node": "/bin/node", "npm": "/bin/npm"},
+		outputs: map[string][]byte{
+			"node": []byte("v20.11.1\n"),
+			"npm":  []byte("10.2.4\n"),
+		},
+	}, dir)
+	if err != nil {
+		t.Fatalf("a missing build toolchain must not fail the check: %v", err)
+	}
+	if len(res.Warnings) != 1 || !strings.HasPrefix(res.Warnings[0], "native-build: sharp may compile native code") {
+		t.Fatalf("expected native toolchain warning, got %v", res.Warnings)
+	}
+}
@@ -0,0 +1,211 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ilaunch/internal/system/semver"
+)
+
+// NodeConstraint is a Node.js version requirement and where it was declared.
+type NodeConstraint struct {
+	// Source names the declaring file, e.g. ".nvmrc" or "package.json engines.node".
+	Source string
+	// Spec is the requirement as written.
+	Spec  string
+	Range semver.Range
+}
+
+func (c NodeConstraint) String() string {
+	return fmt.Sprintf("%s (from %s)", c.Spec, c.Source)
+}
+
+// defaultNodeConstraint applies when the project declares no requirement.
+var defaultNodeConstraint = NodeConstraint{
+	Source: "iLaunch default",
+	Spec:   fmt.Sprintf(">=%d", MinNodeMajor),
+	Range:  semver.MustParseRange(fmt.Sprintf(">=%d", MinNodeMajor)),
+}
+
+// ltsMajors maps the codenames accepted by nvm as lts/<name> to their major
+// release line.
+var ltsMajors = map[string]uint64{
+	"argon":    4,
+	"boron":    6,
+	"carbon":   8,
+	"dubnium":  10,
+	"erbium":   12,
+	"fermium":  14,
+	"gallium":  16,
+	"hydrogen": 18,
+	"iron":     20,
+	"jod":      22,
+	"krypton":  24,
+}
+
+// NodeConstraints collects the Node.js requirements declared in dir by
+// .nvmrc, .node-version, .tool-versions and package.json engines.node, in
+// that order. Aliases that do not pin a version, such as "node" or
+// "system", are skipped.
+func NodeConstraints(dir string) ([]NodeConstraint, error) {
+	constraints := make([]NodeConstraint, 0)
+	add := func(source, spec string) error {
+		rng, ok, err := parseNodeSpec(spec)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		if ok {
+			constraints = append(constraints, NodeConstraint{Source: source, Spec: spec, Range: rng})
+		}
+		return nil
+	}
+	for _, name := range []string{".nvmrc", ".node-version"} {
+		spec, err := readVersionFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if spec != "" {
+			if err = add(name, spec); err != nil {
+				return nil, err
+			}
+		}
+	}
+	spec, err := readToolVersions(filepath.Join(dir, ".tool-versions"))
+	if err != nil {
+		return nil, err
+	}
+	if spec != "" {
+		if err = add(".tool-versions", spec); err != nil {
+			return nil, err
+		}
+	}
+	pkg, _, err := readPackageJSON(dir)
+	if err != nil {
+		return nil, err
+	}
+	if spec := strings.TrimSpace(pkg.Engines["node"]); spec != "" {
+		if err = add("package.json engines.node", spec); err != nil {
+			return nil, err
+		}
+	}
+	return constraints, nil
+}
+
+// parseNodeSpec understands the version syntax of .nvmrc and friends in
+// addition to semver ranges: "lts/iron", "lts/*" and unpinned aliases.
+func parseNodeSpec(spec string) (semver.Range, bool, error) {
+	lower := strings.ToLower(spec)
+	switch lower {
+	case "node", "stable", "latest", "current", "system":
+		return semver.Range{}, false, nil
+	case "lts/*", "lts":
+		// nvm resolves lts/* to the newest LTS release line.
+		var newest uint64
+		for _, major := range ltsMajors {
+			newest = max(newest, major)
+		}
+		return semver.MustParseRange(fmt.Sprintf("^%d", newest)), true, nil
+	}
+	if name, ok := strings.CutPrefix(lower, "lts/"); ok {
+		major, known := ltsMajors[name]
+		if !known {
+			return semver.Range{}, false, fmt.Errorf("unknown LTS codename %q", name)
+		}
+		return semver.MustParseRange(fmt.Sprintf("^%d", major)), true, nil
+	}
+	rng, err := semver.ParseRange(spec)
+	if err != nil {
+		return semver.Range{}, false, err
+	}
+	return rng, true, nil
+}
+
+// readVersionFile returns the first non-comment line of an .nvmrc-style
+// file, or "" when it does not exist.
+func readVersionFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", path, err)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			return line, nil
+		}
+	}
+	return "", nil
+}
+
+// readToolVersions returns the preferred nodejs version of an asdf/mise
+// .tool-versions file, which lists a tool followed by one or more versions.
+func readToolVersions(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", path, err)
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && (fields[0] == "nodejs" || fields[0] == "node") {
+			return fields[1], nil
+		}
+	}
+	return "", nil
+}
+
+// CheckNodeVersion verifies that version satisfies every constraint, or
+// the iLaunch default when there are none, and names the file of the first
+// one it violates.
+func CheckNodeVersion(version string, constraints []NodeConstraint) error {
+	v, err := semver.Parse(version)
+	if err != nil {
+		return fmt.Errorf("parse node version: %w", err)
+	}
+	if len(constraints) == 0 {
+		constraints = []NodeConstraint{defaultNodeConstraint}
+	}
+	for _, c := range constraints {
+		if !c.Range.Contains(v) {
+			return &NodeVersionError{Version: version, Constraint: c}
+		}
+	}
+	return nil
+}
+
+// NodeVersionError reports an installed node that violates a constraint.
+type NodeVersionError struct {
+	Version    string
+	Constraint NodeConstraint
+}
+
+func (e *NodeVersionError) Error() string {
+	return fmt.Sprintf("node %s does not satisfy %s", e.Version, e.Constraint)
+}
+
+// SatisfiesAll reports whether v meets every constraint, or the default
+// requirement when there are none.
+func SatisfiesAll(v semver.Version, constraints []NodeConstraint) bool {
+	if len(constraints) == 0 {
+		constraints = []NodeConstraint{defaultNodeConstraint}
+	}
+	for _, c := range constraints {
+		if !c.Range.Contains(v) {
+			return false
+		}
+	}
+	return true
+}
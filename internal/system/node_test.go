@@ -0,0 +1,83 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestNodeConstraints(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".nvmrc":         "# pinned for CI\nv20.11.1\n",
+		".node-version":  "node\n",
+		".tool-versions": "python 3.12.1\nnodejs 20.11.1 18.19.0\n",
+		"package.json":   `{"name": "demo", "engines": {"node": ">=18.17 <21"}}`,
+	})
+	got, err := NodeConstraints(dir)
+	if err != nil {
+		t.Fatalf("NodeConstraints() error = %v", err)
+	}
+	want := []string{
+		"v20.11.1 (from .nvmrc)",
+		"20.11.1 (from .tool-versions)",
+		">=18.17 <21 (from package.json engines.node)",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i].String() != want[i] {
+			t.Fatalf("constraint %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNodeConstraintsInvalid(t *testing.T) {
+	dir := writeFiles(t, map[string]string{".nvmrc": "lts/unknown\n"})
+	if _, err := NodeConstraints(dir); err == nil || !strings.Contains(err.Error(), ".nvmrc") {
+		t.Fatalf("expected error naming .nvmrc, got %v", err)
+	}
+}
+
+func TestCheckNodeVersion(t *testing.T) {
+	constraint := func(spec string) []NodeConstraint {
+		rng, _, err := parseNodeSpec(spec)
+		if err != nil {
+			t.Fatalf("parseNodeSpec(%q) error = %v", spec, err)
+		}
+		return []NodeConstraint{{Source: "test", Spec: spec, Range: rng}}
+	}
+	tests := []struct {
+		version     string
+		constraints []NodeConstraint
+		wantErr     bool
+	}{
+		{"v18.16.0", nil, false},
+		{"v16.20.2", nil, true},
+		{"v18.16.0", constraint(">=18.17 <21"), true},
+		{"v20.11.1", constraint("^20.11"), false},
+		{"v20.11.1", constraint("lts/iron"), false},
+		{"v22.1.0", constraint("lts/iron"), true},
+		{"v24.1.0", constraint("lts/*"), false},
+		{"v22.1.0", constraint("lts/*"), true},
+		{"garbage", nil, true},
+	}
+	for _, tt := range tests {
+		err := CheckNodeVersion(tt.version, tt.constraints)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("CheckNodeVersion(%s, %v) error = %v, wantErr %v", tt.version, tt.constraints, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package system
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// packageJSON holds the package.json fields the checks care about.
+type packageJSON struct {
+	Engines              map[string]string `json:"engines"`
+	PackageManager       string            `json:"packageManager"`
+	Workspaces           workspaces        `json:"workspaces"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+}
+
+// readPackageJSON reads dir/package.json. A missing file is not an error;
+// ok reports whether it exists.
+func readPackageJSON(dir string) (pkg packageJSON, ok bool, err error) {
+	path := filepath.Join(dir, "package.json")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return packageJSON{}, false, nil
+	}
+	if err != nil {
+		return packageJSON{}, false, fmt.Errorf("read %s: %w", path, err)
+	}
+	if err = json.Unmarshal(data, &pkg); err != nil {
+		return packageJSON{}, false, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return pkg, true, nil
+}
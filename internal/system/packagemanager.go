@@ -0,0 +1,174 @@
+package system
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"ilaunch/internal/system/semver"
+)
+
+// PackageManager abstracts the commands that differ between npm, pnpm,
+// Yarn and Bun, so callers never hard-code argument lists.
+type PackageManager interface {
+	// Name is both the executable and the name used by the packageManager
+	// and engines fields of package.json.
+	Name() string
+	Version() semver.Version
+	// InstallArgs returns the arguments that install dependencies. With
+	// frozen set the install fails instead of updating the lockfile.
+	InstallArgs(frozen bool) []string
+	// Workspaces returns the workspace package patterns declared in dir.
+	// Installing from the root covers every workspace for all managers.
+	Workspaces(dir string) ([]string, error)
+}
+
+// NewPackageManager returns the implementation for name. Yarn 2 and later
+// ("berry") differ from Yarn 1 enough to be a separate implementation.
+func NewPackageManager(name string, version semver.Version) (PackageManager, error) {
+	base := baseManager{name: name, version: version}
+	switch name {
+	case "npm":
+		return npmManager{base}, nil
+	case "pnpm":
+		return pnpmManager{base}, nil
+	case "yarn":
+		if version.Major < 2 {
+			return yarnClassicManager{base}, nil
+		}
+		return yarnBerryManager{base}, nil
+	case "bun":
+		return bunManager{base}, nil
+	}
+	return nil, fmt.Errorf("unsupported package manager %q", name)
+}
+
+type baseManager struct {
+	name    string
+	version semver.Version
+}
+
+func (b baseManager) Name() string {
+	return b.name
+}
+
+func (b baseManager) Version() semver.Version {
+	return b.version
+}
+
+// Workspaces reads the "workspaces" field of package.json, which npm, Yarn
+// and Bun share.
+func (b baseManager) Workspaces(dir string) ([]string, error) {
+	pkg, _, err := readPackageJSON(dir)
+	if err != nil {
+		return nil, err
+	}
+	return pkg.Workspaces.Packages, nil
+}
+
+type npmManager struct{ baseManager }
+
+func (npmManager) InstallArgs(frozen bool) []string {
+	if frozen {
+		return []string{"ci"}
+	}
+	return []string{"install"}
+}
+
+type pnpmManager struct{ baseManager }
+
+func (pnpmManager) InstallArgs(frozen bool) []string {
+	if frozen {
+		return []string{"install", "--frozen-lockfile"}
+	}
+	return []string{"install"}
+}
+
+// Workspaces reads pnpm-workspace.yaml; pnpm ignores package.json workspaces.
+func (pnpmManager) Workspaces(dir string) ([]string, error) {
+	path := filepath.Join(dir, "pnpm-workspace.yaml")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return parseWorkspaceYAML(data), nil
+}
+
+type yarnClassicManager struct{ baseManager }
+
+func (yarnClassicManager) InstallArgs(frozen bool) []string {
+	if frozen {
+		return []string{"install", "--frozen-lockfile"}
+	}
+	return []string{"install"}
+}
+
+type yarnBerryManager struct{ baseManager }
+
+// InstallArgs uses --immutable, which replaced --frozen-lockfile in Yarn 2.
+func (yarnBerryManager) InstallArgs(frozen bool) []string {
+	if frozen {
+		return []string{"install", "--immutable"}
+	}
+	return []string{"install"}
+}
+
+type bunManager struct{ baseManager }
+
+func (bunManager) InstallArgs(frozen bool) []string {
+	if frozen {
+		return []string{"install", "--frozen-lockfile"}
+	}
+	return []string{"install"}
+}
+
+// workspaces is the package.json "workspaces" field: either a list of
+// patterns or, in Yarn 1, an object with a "packages" list.
+type workspaces struct {
+	Packages []string
+}
+
+func (w *workspaces) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &w.Packages); err == nil {
+		return nil
+	}
+	var obj struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("workspaces: expected a list or an object with packages")
+	}
+	w.Packages = obj.Packages
+	return nil
+}
+
+// parseWorkspaceYAML extracts the "packages" list of pnpm-workspace.yaml.
+// Only the block sequence form used by pnpm's documentation is supported.
+func parseWorkspaceYAML(data []byte) []string {
+	patterns := make([]string, 0)
+	inPackages := false
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			continue
+		case !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-"):
+			inPackages = trimmed == "packages:"
+		case inPackages && strings.HasPrefix(trimmed, "-"):
+			item := strings.TrimSpace(strings.TrimPrefix(trimmed, "-"))
+			patterns = append(patterns, strings.Trim(item, `"'`))
+		}
+	}
+	return patterns
+}
@@ -0,0 +1,67 @@
+package system
+
+import (
+	"strings"
+	"testing"
+
+	"ilaunch/internal/system/semver"
+)
+
+func TestPackageManagerInstallArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		frozen  bool
+		want    string
+	}{
+		{"npm", "10.5.0", false, "install"},
+		{"npm", "10.5.0", true, "ci"},
+		{"pnpm", "9.12.0", true, "install --frozen-lockfile"},
+		{"yarn", "1.22.22", true, "install --frozen-lockfile"},
+		{"yarn", "4.1.0", true, "install --immutable"},
+		{"yarn", "4.1.0", false, "install"},
+		{"bun", "1.1.20", true, "install --frozen-lockfile"},
+	}
+	for _, tt := range tests {
+		pm, err := NewPackageManager(tt.name, semver.MustParse(tt.version))
+		if err != nil {
+			t.Fatalf("NewPackageManager(%s) error = %v", tt.name, err)
+		}
+		if got := strings.Join(pm.InstallArgs(tt.frozen), " "); got != tt.want {
+			t.Fatalf("%s %s InstallArgs(%v) = %q, want %q", tt.name, tt.version, tt.frozen, got, tt.want)
+		}
+	}
+	if _, err := NewPackageManager("deno", semver.Version{}); err == nil {
+		t.Fatal("expected error for unsupported package manager")
+	}
+}
+
+func TestPackageManagerWorkspaces(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"npm", map[string]string{"package.json": `{"workspaces": ["packages/*", "apps/*"]}`}, "packages/*,apps/*"},
+		{"yarn", map[string]string{"package.json": `{"workspaces": {"packages": ["packages/*"], "nohoist": ["**/rn"]}}`}, "packages/*"},
+		{"bun", map[string]string{"package.json": `{"name": "single"}`}, ""},
+		{"pnpm", map[string]string{
+			"package.json":        `{"workspaces": ["ignored/*"]}`,
+			"pnpm-workspace.yaml": "packages:\n  - 'packages/*'\n  - \"apps/*\" # apps\n  - '!**/test/**'\ncatalog:\n  react: ^18\n",
+		}, "packages/*,apps/*,!**/test/**"},
+		{"pnpm", nil, ""},
+	}
+	for _, tt := range tests {
+		pm, err := NewPackageManager(tt.name, semver.MustParse("4.0.0"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := pm.Workspaces(writeFiles(t, tt.files))
+		if err != nil {
+			t.Fatalf("%s Workspaces() error = %v", tt.name, err)
+		}
+		if strings.Join(got, ",") != tt.want {
+			t.Fatalf("%s Workspaces() = %v, want %s", tt.name, got, tt.want)
+		}
+	}
+}
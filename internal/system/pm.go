@@ -0,0 +1,109 @@
+package system
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type lockfile struct {
+	file    string
+	manager string
+}
+
+// lockfiles maps each lockfile to the package manager that writes it, in
+// the order they are looked for.
+var lockfiles = []lockfile{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"package-lock.json", "npm"},
+	{"npm-shrinkwrap.json", "npm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lockb", "bun"},
+	{"bun.lock", "bun"},
+}
+
+// pathManagers are tried in order when the project does not say which
+// package manager it uses.
+var pathManagers = []string{"pnpm", "npm", "yarn", "bun"}
+
+// PackageManagerSpec is the package manager a project uses and how it was
+// chosen.
+type PackageManagerSpec struct {
+	Name string
+	// Version is the exact version pinned by the packageManager field, if any.
+	Version string
+	// Source explains the choice: "package.json packageManager", a
+	// lockfile name, or "PATH".
+	Source string
+}
+
+// DetectPackageManager follows the project: the package.json packageManager
+// field first, then the lockfile present, then the first package manager
+// found on PATH. Lockfiles of other package managers are reported by the
+// lockfiles check.
+func DetectPackageManager(dir string, commander Commander) (PackageManagerSpec, error) {
+	pkg, _, err := readPackageJSON(dir)
+	if err != nil {
+		return PackageManagerSpec{}, err
+	}
+	locks, err := findLockfiles(dir)
+	if err != nil {
+		return PackageManagerSpec{}, err
+	}
+
+	var spec PackageManagerSpec
+	switch {
+	case pkg.PackageManager != "":
+		name, version, err := parsePackageManagerField(pkg.PackageManager)
+		if err != nil {
+			return PackageManagerSpec{}, err
+		}
+		spec = PackageManagerSpec{Name: name, Version: version, Source: "package.json packageManager"}
+	case len(locks) > 0:
+		spec = PackageManagerSpec{Name: locks[0].manager, Source: locks[0].file}
+	default:
+		for _, name := range pathManagers {
+			if _, err := commander.LookPath(name); err == nil {
+				return PackageManagerSpec{Name: name, Source: "PATH"}, nil
+			}
+		}
+		return PackageManagerSpec{}, fmt.Errorf("no package manager found in PATH (looked for %s)", strings.Join(pathManagers, ", "))
+	}
+
+	if _, err := commander.LookPath(spec.Name); err != nil {
+		if spec.Version != "" {
+			return spec, &ProvisionError{Provision{Name: spec.Name, Version: spec.Version}}
+		}
+		return PackageManagerSpec{}, fmt.Errorf("%s (from %s) not found in PATH", spec.Name, spec.Source)
+	}
+	return spec, nil
+}
+
+func findLockfiles(dir string) ([]lockfile, error) {
+	found := make([]lockfile, 0)
+	for _, l := range lockfiles {
+		_, err := os.Stat(filepath.Join(dir, l.file))
+		switch {
+		case err == nil:
+			found = append(found, l)
+		case !errors.Is(err, fs.ErrNotExist):
+			return nil, fmt.Errorf("check %s: %w", l.file, err)
+		}
+	}
+	return found, nil
+}
+
+// parsePackageManagerField splits a corepack-style "pnpm@9.12.0+sha512.…"
+// into name and version.
+func parsePackageManagerField(field string) (string, string, error) {
+	name, version, _ := strings.Cut(strings.TrimSpace(field), "@")
+	version, _, _ = strings.Cut(version, "+")
+	if !slices.Contains(pathManagers, name) {
+		return "", "", fmt.Errorf("package.json packageManager: unsupported package manager %q", name)
+	}
+	return name, version, nil
+}
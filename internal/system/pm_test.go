@@ -0,0 +1,111 @@
+package system
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDetectPackageManager(t *testing.T) {
+	all := fakeCommander{paths: map[string]string{"pnpm": "/bin/pnpm", "npm": "/bin/npm", "yarn": "/bin/yarn", "bun": "/bin/bun"}}
+	tests := []struct {
+		name        string
+		files       map[string]string
+		commander   fakeCommander
+		want        string
+		wantVersion string
+		wantSource  string
+		wantWarning string
+		wantErr     string
+	}{
+		{
+			name:        "packageManager field wins",
+			files:       map[string]string{"package.json": `{"packageManager": "yarn@4.1.0+sha512.abc"}`, "package-lock.json": "{}"},
+			commander:   all,
+			want:        "yarn",
+			wantVersion: "4.1.0",
+			wantSource:  "package.json packageManager",
+			wantWarning: "package-lock.json (npm) found, but package.json pins yarn",
+		},
+		{
+			name:       "lockfile over PATH preference",
+			files:      map[string]string{"package-lock.json": "{}"},
+			commander:  all,
+			want:       "npm",
+			wantSource: "package-lock.json",
+		},
+		{
+			name:       "bun text lockfile",
+			files:      map[string]string{"bun.lock": ""},
+			commander:  all,
+			want:       "bun",
+			wantSource: "bun.lock",
+		},
+		{
+			name:        "conflicting lockfiles",
+			files:       map[string]string{"pnpm-lock.yaml": "", "yarn.lock": ""},
+			commander:   all,
+			want:        "pnpm",
+			wantSource:  "pnpm-lock.yaml",
+			wantWarning: "yarn.lock",
+		},
+		{
+			name:       "PATH fallback",
+			commander:  fakeCommander{paths: map[string]string{"npm": "/bin/npm"}},
+			want:       "npm",
+			wantSource: "PATH",
+		},
+		{
+			name:      "lockfile manager missing",
+			files:     map[string]string{"yarn.lock": ""},
+			commander: fakeCommander{paths: map[string]string{"npm": "/bin/npm"}},
+			wantErr:   "yarn (from yarn.lock) not found in PATH",
+		},
+		{
+			name:      "unsupported packageManager",
+			files:     map[string]string{"package.json": `{"packageManager": "deno@2.0.0"}`},
+			commander: all,
+			wantErr:   `unsupported package manager "deno"`,
+		},
+		{
+			name:      "nothing installed",
+			commander: fakeCommander{},
+			wantErr:   "no package manager found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			spec, err := DetectPackageManager(dir, tt.commander)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DetectPackageManager() error = %v", err)
+			}
+			if spec.Name != tt.want || spec.Version != tt.wantVersion || spec.Source != tt.wantSource {
+				t.Fatalf("got %+v, want %s@%s from %s", spec, tt.want, tt.wantVersion, tt.wantSource)
+			}
+			// Lockfiles of other package managers are left to the lockfiles check.
+			d := diagnoseLockfiles(dir)
+			if (tt.wantWarning == "") != (d.Status == StatusPass) || !strings.Contains(d.Detail, tt.wantWarning) {
+				t.Fatalf("lockfiles = %s %q, want warning %q", d.Status, d.Detail, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestDetectPackageManagerPinnedMissing(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"package.json": `{"packageManager": "pnpm@9.12.0"}`})
+	_, err := DetectPackageManager(dir, fakeCommander{paths: map[string]string{"npm": "/bin/npm"}})
+	var perr *ProvisionError
+	if !errors.As(err, &perr) {
+		t.Fatalf("expected ProvisionError, got %v", err)
+	}
+	if perr.Provision != (Provision{Name: "pnpm", Version: "9.12.0"}) {
+		t.Fatalf("Provision = %+v", perr.Provision)
+	}
+}
@@ -0,0 +1,139 @@
+package system
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"ilaunch/internal/system/semver"
+)
+
+// ProjectConfigFile declares the project's own prerequisites.
+const ProjectConfigFile = ".ilaunch.json"
+
+// commandCheckSpec is a check declared in ProjectConfigFile: run Command
+// and, when Version or Range is set, compare the version it prints.
+type commandCheckSpec struct {
+	ID      string   `json:"id"`
+	Command []string `json:"command"`
+	// Version is a regular expression locating the version in the output;
+	// its first group is used when it has one. By default the first
+	// version-like token is used.
+	Version   string   `json:"version"`
+	Range     string   `json:"range"`
+	Severity  Severity `json:"severity"`
+	DependsOn []string `json:"dependsOn"`
+	Hint      string   `json:"hint"`
+}
+
+type commandCheck struct {
+	spec    commandCheckSpec
+	pattern *regexp.Regexp
+}
+
+// LoadProjectChecks reads the checks declared in dir's ProjectConfigFile. A
+// missing file declares none.
+func LoadProjectChecks(dir string) ([]Check, error) {
+	path := filepath.Join(dir, ProjectConfigFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	var cfg struct {
+		Checks []commandCheckSpec `json:"checks"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	checks := make([]Check, 0, len(cfg.Checks))
+	for i, spec := range cfg.Checks {
+		c, err := newCommandCheck(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: checks[%d]: %w", path, i, err)
+		}
+		checks = append(checks, c)
+	}
+	return checks, nil
+}
+
+func newCommandCheck(spec commandCheckSpec) (commandCheck, error) {
+	if spec.ID == "" {
+		return commandCheck{}, fmt.Errorf("id is required")
+	}
+	if len(spec.Command) == 0 || spec.Command[0] == "" {
+		return commandCheck{}, fmt.Errorf("check %s: command is required", spec.ID)
+	}
+	c := commandCheck{spec: spec}
+	if spec.Version != "" {
+		re, err := regexp.Compile(spec.Version)
+		if err != nil {
+			return commandCheck{}, fmt.Errorf("check %s: version: %w", spec.ID, err)
+		}
+		c.pattern = re
+	}
+	if spec.Range != "" {
+		if _, err := semver.ParseRange(spec.Range); err != nil {
+			return commandCheck{}, fmt.Errorf("check %s: range: %w", spec.ID, err)
+		}
+	}
+	return c, nil
+}
+
+func (c commandCheck) ID() string          { return c.spec.ID }
+func (c commandCheck) Severity() Severity  { return c.spec.Severity }
+func (c commandCheck) DependsOn() []string { return c.spec.DependsOn }
+
+func (c commandCheck) Run(ctx context.Context, commander Commander, _ string) Diagnosis {
+	name, cmdline := c.spec.Command[0], strings.Join(c.spec.Command, " ")
+	if _, err := commander.LookPath(name); err != nil {
+		return fail(name+" not found in PATH", c.hint("install "+strings.TrimSpace(name+" "+c.spec.Range)))
+	}
+	out, err := commander.Output(ctx, name, c.spec.Command[1:]...)
+	if err != nil {
+		return fail(fmt.Sprintf("%s: %v", cmdline, err), c.hint("make sure "+cmdline+" succeeds"))
+	}
+	if c.pattern == nil && c.spec.Range == "" {
+		return pass(cmdline + " succeeded")
+	}
+	raw := string(out)
+	if c.pattern != nil {
+		m := c.pattern.FindStringSubmatch(raw)
+		if m == nil {
+			return fail(fmt.Sprintf("%s: output does not match %q", cmdline, c.spec.Version), c.hint("fix the version pattern in "+ProjectConfigFile))
+		}
+		raw = m[0]
+		if len(m) > 1 {
+			raw = m[1]
+		}
+	}
+	v, err := semver.Coerce(raw)
+	if err != nil {
+		return fail(fmt.Sprintf("read %s version: %v", name, err), c.hint("fix the version pattern in "+ProjectConfigFile))
+	}
+	if c.spec.Range != "" {
+		if err = CheckVersion(name, v, c.spec.Range, ProjectConfigFile); err != nil {
+			return fail(err.Error(), c.hint("install "+name+" "+c.spec.Range))
+		}
+	}
+	return pass(fmt.Sprintf("%s %s", name, v))
+}
+
+// hint returns the hint of the declaration, or fallback.
+func (c commandCheck) hint(fallback string) string {
+	if c.spec.Hint != "" {
+		return c.spec.Hint
+	}
+	return fallback
+}
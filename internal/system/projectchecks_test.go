@@ -0,0 +1,174 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const psqlConfig = `{
+  "checks": [
+    {
+      "id": "psql",
+      "command": ["psql", "--version"],
+      "version": "PostgreSQL\\) (\\S+)",
+      "range": ">=15 <17",
+      "hint": "brew install postgresql@16"
+    },
+    {
+      "id": "docker",
+      "command": ["docker", "info"],
+      "severity": "warning"
+    }
+  ]
+}`
+
+func TestLoadProjectChecks(t *testing.T) {
+	checks, err := LoadProjectChecks(t.TempDir())
+	if err != nil || checks != nil {
+		t.Fatalf("missing config: got %v, %v", checks, err)
+	}
+
+	checks, err = LoadProjectChecks(writeFiles(t, map[string]string{ProjectConfigFile: psqlConfig}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 2 || checks[0].ID() != "psql" || checks[1].Severity() != SeverityWarning {
+		t.Fatalf("unexpected checks: %+v", checks)
+	}
+
+	invalid := map[string]string{
+		"unknown field":  `{"checks": [{"id": "x", "command": ["x"], "min": "1"}]}`,
+		"missing id":     `{"checks": [{"command": ["x"]}]}`,
+		"missing cmd":    `{"checks": [{"id": "x"}]}`,
+		"bad pattern":    `{"checks": [{"id": "x", "command": ["x"], "version": "("}]}`,
+		"bad range":      `{"checks": [{"id": "x", "command": ["x"], "range": ">=banana"}]}`,
+		"bad severity":   `{"checks": [{"id": "x", "command": ["x"], "severity": "fatal"}]}`,
+		"unknown parent": `{"checks": [{"id": "x", "command": ["x"], "dependsOn": ["y"]}]}`,
+	}
+	for name, cfg := range invalid {
+		t.Run(name, func(t *testing.T) {
+			_, err := ProjectRegistry(writeFiles(t, map[string]string{ProjectConfigFile: cfg}))
+			if err == nil || !strings.Contains(err.Error(), ProjectConfigFile) {
+				t.Fatalf("expected error naming %s, got %v", ProjectConfigFile, err)
+			}
+		})
+	}
+}
+
+func TestCommandCheckRun(t *testing.T) {
+	psql := commandCheckSpec{ID: "psql", Command: []string{"psql", "--version"}, Version: `PostgreSQL\) (\S+)`, Range: ">=15 <17"}
+	paths := map[string]string{"psql": "/bin/psql", "docker": "/bin/docker"}
+	tests := []struct {
+		name      string
+		spec      commandCheckSpec
+		commander fakeCommander
+		want      Status
+		detail    string
+	}{
+		{name: "in range", spec: psql, commander: fakeCommander{paths: paths, out: []byte("psql (PostgreSQL) 16.2\n")}, want: StatusPass, detail: "psql 16.2.0"},
+		{name: "out of range", spec: psql, commander: fakeCommander{paths: paths, out: []byte("psql (PostgreSQL) 14.11\n")}, want: StatusFail, detail: "psql 14.11.0 does not satisfy >=15 <17 (from .ilaunch.json)"},
+		{name: "pattern mismatch", spec: psql, commander: fakeCommander{paths: paths, out: []byte("psql 16\n")}, want: StatusFail, detail: "output does not match"},
+		{name: "not installed", spec: psql, commander: fakeCommander{}, want: StatusFail, detail: "psql not found in PATH"},
+		{
+			name:      "range without pattern",
+			spec:      commandCheckSpec{ID: "docker-version", Command: []string{"docker", "--version"}, Range: ">=24"},
+			commander: fakeCommander{paths: paths, out: []byte("Docker version 27.3.1, build ce12230\n")},
+			want:      StatusPass,
+			detail:    "docker 27.3.1",
+		},
+		{
+			name:      "command only",
+			spec:      commandCheckSpec{ID: "docker", Command: []string{"docker", "info"}},
+			commander: fakeCommander{paths: paths, err: errors.New("exit status 1")},
+			want:      StatusFail,
+			detail:    "docker info: exit status 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newCommandCheck(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := c.Run(context.Background(), tt.commander, "")
+			if got.Status != tt.want || !strings.Contains(got.Detail, tt.detail) {
+				t.Fatalf("got %s %q, want %s containing %q", got.Status, got.Detail, tt.want, tt.detail)
+			}
+			if got.Status == StatusFail && got.Hint == "" {
+				t.Fatal("expected a hint")
+			}
+		})
+	}
+}
+
+func TestCheckEnvironmentRunsProjectChecks(t *testing.T) {
+	commander := fakeCommander{
+		paths: map[string]string{"node": "/bin/node", "npm": "/bin/npm", "psql": "/bin/psql"},
+		outputs: map[string][]byte{
+			"node": []byte("v20.11.1\n"),
+			"npm":  []byte("10.2.4\n"),
+			"psql": []byte("psql (PostgreSQL) 14.11\n"),
+		},
+	}
+	_, err := CheckEnvironment(context.Background(), commander, writeFiles(t, map[string]string{ProjectConfigFile: psqlConfig}))
+	if err == nil || !strings.Contains(err.Error(), "check psql: psql 14.11.0 does not satisfy") || !strings.Contains(err.Error(), "brew install postgresql@16") {
+		t.Fatalf("expected psql failure with hint, got %v", err)
+	}
+
+	commander.outputs["psql"] = []byte("psql (PostgreSQL) 16.2\n")
+	res, err := CheckEnvironment(context.Background(), commander, writeFiles(t, map[string]string{ProjectConfigFile: psqlConfig}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Warnings) != 1 || !strings.HasPrefix(res.Warnings[0], "docker: docker not found in PATH") {
+		t.Fatalf("expected docker warning, got %v", res.Warnings)
+	}
+}
+
+// countingCommander counts the lookups of one command.
+type countingCommander struct {
+	fakeCommander
+	name    string
+	lookups *atomic.Int32
+}
+
+func (c countingCommander) LookPath(file string) (string, error) {
+	if file == c.name {
+		c.lookups.Add(1)
+	}
+	return c.fakeCommander.LookPath(file)
+}
+
+func TestCheckEnvironmentReportsSkippedProjectChecks(t *testing.T) {
+	dir := writeFiles(t, map[string]string{ProjectConfigFile: `{"checks": [{"id": "hooks", "command": ["git", "config", "core.hooksPath"], "dependsOn": ["git"]}]}`})
+	commander := countingCommander{
+		fakeCommander: fakeCommander{
+			paths: map[string]string{"node": "/bin/node", "npm": "/bin/npm"},
+			outputs: map[string][]byte{
+				"node": []byte("v20.11.1\n"),
+				"npm":  []byte("10.2.4\n"),
+			},
+		},
+		name:    "git",
+		lookups: new(atomic.Int32),
+	}
+	res, err := CheckEnvironment(context.Background(), commander, dir)
+	if err != nil {
+		t.Fatalf("a built-in dependency of a project check must not block the bootstrap: %v", err)
+	}
+	if n := commander.lookups.Load(); n != 1 {
+		t.Fatalf("git check ran %d times, want 1", n)
+	}
+	want := []string{"git: git not found in PATH", "hooks: skipped: git failed"}
+	if len(res.Warnings) != len(want) {
+		t.Fatalf("Warnings = %v, want %v", res.Warnings, want)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(res.Warnings[i], w) {
+			t.Fatalf("Warnings[%d] = %q, want prefix %q", i, res.Warnings[i], w)
+		}
+	}
+}
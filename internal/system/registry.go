@@ -0,0 +1,230 @@
+package system
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Severity says whether a failing check blocks the bootstrap.
+type Severity int
+
+const (
+	// SeverityError checks fail the bootstrap and doctor.
+	SeverityError Severity = iota
+	// SeverityWarning checks report their failures as warnings.
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "", "error":
+		*s = SeverityError
+	case "warning", "warn":
+		*s = SeverityWarning
+	default:
+		return fmt.Errorf("unknown severity %q (expected error or warning)", text)
+	}
+	return nil
+}
+
+// Check is a prerequisite of the machine or the project. Run reports on
+// the project in dir and should use commander for every external command,
+// so it can be tested with a fake.
+type Check interface {
+	// ID names the check in reports and in the dependencies of other checks.
+	ID() string
+	Severity() Severity
+	// DependsOn lists the checks that must not fail for this one to run.
+	DependsOn() []string
+	Run(ctx context.Context, commander Commander, dir string) Diagnosis
+}
+
+type funcCheck struct {
+	id        string
+	severity  Severity
+	dependsOn []string
+	run       func(ctx context.Context, commander Commander, dir string) Diagnosis
+}
+
+// NewCheck returns a Check that calls run.
+func NewCheck(id string, severity Severity, dependsOn []string, run func(ctx context.Context, commander Commander, dir string) Diagnosis) Check {
+	return funcCheck{id: id, severity: severity, dependsOn: dependsOn, run: run}
+}
+
+func (c funcCheck) ID() string          { return c.id }
+func (c funcCheck) Severity() Severity  { return c.severity }
+func (c funcCheck) DependsOn() []string { return c.dependsOn }
+
+func (c funcCheck) Run(ctx context.Context, commander Commander, dir string) Diagnosis {
+	return c.run(ctx, commander, dir)
+}
+
+// Registry is an ordered set of checks. It is not safe to register checks
+// while the registry runs.
+type Registry struct {
+	checks []Check
+}
+
+// DefaultRegistry holds the built-in checks. Checks registered on it from
+// Go, typically in an init function, run in every project alongside those
+// of ProjectConfigFile.
+var DefaultRegistry = mustRegistry(BuiltinChecks()...)
+
+func mustRegistry(checks ...Check) *Registry {
+	r := &Registry{}
+	if err := r.Register(checks...); err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Register adds checks in order. IDs must be unique and dependencies must be
+// registered first, which keeps the dependency graph free of cycles.
+func (r *Registry) Register(checks ...Check) error {
+	for _, c := range checks {
+		id := c.ID()
+		if id == "" {
+			return fmt.Errorf("register check: empty ID")
+		}
+		if r.index(id) >= 0 {
+			return fmt.Errorf("register check %s: already registered", id)
+		}
+		for _, dep := range c.DependsOn() {
+			if r.index(dep) < 0 {
+				return fmt.Errorf("register check %s: unknown dependency %s (dependencies must be registered first)", id, dep)
+			}
+		}
+		r.checks = append(r.checks, c)
+	}
+	return nil
+}
+
+// Checks returns the registered checks in order.
+func (r *Registry) Checks() []Check {
+	return slices.Clone(r.checks)
+}
+
+func (r *Registry) index(id string) int {
+	return slices.IndexFunc(r.checks, func(c Check) bool { return c.ID() == id })
+}
+
+// Select returns a registry with the checks named by ids and those they
+// depend on, directly or not.
+func (r *Registry) Select(ids ...string) (*Registry, error) {
+	keep := make([]bool, len(r.checks))
+	var mark func(id string) error
+	mark = func(id string) error {
+		i := r.index(id)
+		if i < 0 {
+			return fmt.Errorf("select check %s: not registered", id)
+		}
+		if keep[i] {
+			return nil
+		}
+		keep[i] = true
+		for _, dep := range r.checks[i].DependsOn() {
+			if err := mark(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, id := range ids {
+		if err := mark(id); err != nil {
+			return nil, err
+		}
+	}
+	selected := &Registry{}
+	for i, c := range r.checks {
+		if keep[i] {
+			selected.checks = append(selected.checks, c)
+		}
+	}
+	return selected, nil
+}
+
+// Run runs the checks concurrently, each once its dependencies are done. A
+// check whose dependency failed or was skipped is skipped, and a failure of
+// a warning check is reported as a warning.
+func (r *Registry) Run(ctx context.Context, commander Commander, dir string) Report {
+	report := Report{Checks: make([]Diagnosis, len(r.checks))}
+	done := make([]chan struct{}, len(r.checks))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(r.checks))
+	for i, c := range r.checks {
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			failed := make([]string, 0)
+			for _, dep := range c.DependsOn() {
+				j := r.index(dep)
+				<-done[j]
+				if s := report.Checks[j].Status; s == StatusFail || s == StatusSkip {
+					failed = append(failed, dep)
+				}
+			}
+			var res Diagnosis
+			if len(failed) > 0 {
+				res = Diagnosis{Status: StatusSkip, Detail: "skipped: " + strings.Join(failed, ", ") + " failed"}
+			} else {
+				res = c.Run(ctx, commander, dir)
+			}
+			if res.Status == StatusFail && c.Severity() == SeverityWarning {
+				res.Status = StatusWarn
+			}
+			res.Check = c.ID()
+			report.Checks[i] = res
+		}()
+	}
+	wg.Wait()
+	for _, c := range report.Checks {
+		report.Status = max(report.Status, c.Status)
+	}
+	return report
+}
+
+// ProjectRegistry returns the checks of DefaultRegistry followed by those
+// declared in the ProjectConfigFile of dir.
+func ProjectRegistry(dir string) (*Registry, error) {
+	project, err := LoadProjectChecks(dir)
+	if err != nil {
+		return nil, err
+	}
+	r := &Registry{checks: DefaultRegistry.Checks()}
+	if err = r.Register(project...); err != nil {
+		return nil, fmt.Errorf("%s: %w", ProjectConfigFile, err)
+	}
+	return r, nil
+}
+
+// extraCheckIDs returns the IDs of the checks in r that are not built in.
+func extraCheckIDs(r *Registry) []string {
+	builtin := make(map[string]bool)
+	for _, c := range BuiltinChecks() {
+		builtin[c.ID()] = true
+	}
+	ids := make([]string, 0)
+	for _, c := range r.checks {
+		if !builtin[c.ID()] {
+			ids = append(ids, c.ID())
+		}
+	}
+	return ids
+}
@@ -0,0 +1,107 @@
+package system
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func staticCheck(id string, severity Severity, status Status, deps ...string) Check {
+	return NewCheck(id, severity, deps, func(context.Context, Commander, string) Diagnosis {
+		return Diagnosis{Status: status, Detail: id}
+	})
+}
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		checks  []Check
+		wantErr string
+	}{
+		{name: "ok", checks: []Check{staticCheck("a", SeverityError, StatusPass), staticCheck("b", SeverityError, StatusPass, "a")}},
+		{name: "empty id", checks: []Check{staticCheck("", SeverityError, StatusPass)}, wantErr: "empty ID"},
+		{name: "duplicate", checks: []Check{staticCheck("a", SeverityError, StatusPass), staticCheck("a", SeverityError, StatusPass)}, wantErr: "already registered"},
+		{name: "dependency registered later", checks: []Check{staticCheck("b", SeverityError, StatusPass, "a"), staticCheck("a", SeverityError, StatusPass)}, wantErr: "unknown dependency a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Registry{}).Register(tt.checks...)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Register() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Register() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistryRun(t *testing.T) {
+	var ranAfterDep atomic.Bool
+	var depDone atomic.Bool
+	r := mustRegistry(
+		NewCheck("base", SeverityError, nil, func(context.Context, Commander, string) Diagnosis {
+			depDone.Store(true)
+			return pass("ok")
+		}),
+		NewCheck("after-base", SeverityError, []string{"base"}, func(context.Context, Commander, string) Diagnosis {
+			ranAfterDep.Store(depDone.Load())
+			return pass("ok")
+		}),
+		staticCheck("broken", SeverityError, StatusFail),
+		staticCheck("needs-broken", SeverityError, StatusPass, "broken"),
+		staticCheck("transitive", SeverityError, StatusPass, "needs-broken"),
+		staticCheck("soft", SeverityWarning, StatusFail),
+		staticCheck("after-soft", SeverityError, StatusPass, "soft"),
+	)
+	report := r.Run(context.Background(), fakeCommander{}, t.TempDir())
+	want := []Status{StatusPass, StatusPass, StatusFail, StatusSkip, StatusSkip, StatusWarn, StatusPass}
+	for i, c := range report.Checks {
+		if c.Check != r.checks[i].ID() || c.Status != want[i] {
+			t.Errorf("checks[%d] = %s %s, want %s %s", i, c.Check, c.Status, r.checks[i].ID(), want[i])
+		}
+	}
+	if !ranAfterDep.Load() {
+		t.Error("after-base ran before base finished")
+	}
+	if got := report.Checks[3].Detail; got != "skipped: broken failed" {
+		t.Errorf("skip detail = %q", got)
+	}
+	if report.Status != StatusFail {
+		t.Errorf("report status = %s, want fail", report.Status)
+	}
+}
+
+func TestRegistrySelect(t *testing.T) {
+	r := mustRegistry(
+		staticCheck("a", SeverityError, StatusPass),
+		staticCheck("b", SeverityError, StatusPass, "a"),
+		staticCheck("c", SeverityError, StatusPass, "b"),
+		staticCheck("d", SeverityError, StatusPass),
+	)
+	selected, err := r.Select("c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]string, 0)
+	for _, c := range selected.Checks() {
+		ids = append(ids, c.ID())
+	}
+	if got := strings.Join(ids, ","); got != "a,b,c" {
+		t.Fatalf("Select(c) = %s, want a,b,c", got)
+	}
+	if _, err = r.Select("missing"); err == nil {
+		t.Fatal("expected error for unknown check")
+	}
+}
+
+func TestSeverityUnmarshalText(t *testing.T) {
+	var s Severity
+	if err := s.UnmarshalText([]byte("warning")); err != nil || s != SeverityWarning {
+		t.Fatalf("got %v, %v", s, err)
+	}
+	if err := s.UnmarshalText([]byte("fatal")); err == nil {
+		t.Fatal("expected error for unknown severity")
+	}
+}
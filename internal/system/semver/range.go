@@ -0,0 +1,270 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+type operator string
+
+const (
+	opEQ operator = "="
+	opGT operator = ">"
+	opGE operator = ">="
+	opLT operator = "<"
+	opLE operator = "<="
+)
+
+type comparator struct {
+	op      operator
+	version Version
+}
+
+func (c comparator) matches(v Version) bool {
+	cmp := v.Compare(c.version)
+	switch c.op {
+	case opGT:
+		return cmp > 0
+	case opGE:
+		return cmp >= 0
+	case opLT:
+		return cmp < 0
+	case opLE:
+		return cmp <= 0
+	default:
+		return cmp == 0
+	}
+}
+
+func (c comparator) String() string {
+	return string(c.op) + c.version.String()
+}
+
+// Range is a set of version constraints such as ">=18.17 <21",
+// "^20.11 || ^22" or "1.2.3 - 2.x". A version satisfies it when it
+// satisfies any of its comparator sets, and a set when it satisfies every
+// comparator in it.
+type Range struct {
+	raw  string
+	sets [][]comparator
+}
+
+// ParseRange parses a range in node-semver syntax: comparator sets joined
+// by "||", each made of space-separated comparators or a hyphen range
+// "A - B". A comparator is an operator (=, >, >=, <, <=, ^ or ~) followed
+// by a version whose trailing components may be omitted or replaced by x,
+// X or *; "20", "20.x" and "^20" all mean any 20.y.z release.
+func ParseRange(s string) (Range, error) {
+	r := Range{raw: strings.TrimSpace(s)}
+	for _, part := range strings.Split(s, "||") {
+		set, err := parseSet(part)
+		if err != nil {
+			return Range{}, fmt.Errorf("invalid range %q: %w", s, err)
+		}
+		r.sets = append(r.sets, set)
+	}
+	return r, nil
+}
+
+// MustParseRange is like ParseRange but panics on error.
+func MustParseRange(s string) Range {
+	r, err := ParseRange(s)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Or returns a range satisfied by any of ranges.
+func Or(ranges ...Range) Range {
+	var out Range
+	raws := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		out.sets = append(out.sets, r.sets...)
+		raws = append(raws, r.String())
+	}
+	out.raw = strings.Join(raws, " || ")
+	return out
+}
+
+func (r Range) String() string {
+	return r.raw
+}
+
+// Contains reports whether v satisfies the range.
+func (r Range) Contains(v Version) bool {
+	for _, set := range r.sets {
+		if setContains(set, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// setContains also applies the node-semver prerelease rule: a prerelease
+// such as 3.0.0-rc.1 only satisfies a set when one of its comparators has
+// a prerelease on the same major.minor.patch, so ">=2" does not opt into
+// release candidates.
+func setContains(set []comparator, v Version) bool {
+	for _, c := range set {
+		if !c.matches(v) {
+			return false
+		}
+	}
+	if len(v.Prerelease) == 0 {
+		return true
+	}
+	for _, c := range set {
+		cv := c.version
+		if len(cv.Prerelease) > 0 && cv.Major == v.Major && cv.Minor == v.Minor && cv.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	anyVersion = comparator{opGE, Version{}}
+	noVersion  = comparator{opLT, Version{Prerelease: []string{"0"}}}
+)
+
+func parseSet(s string) ([]comparator, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		// An empty set, like "*", allows any version.
+		return []comparator{anyVersion}, nil
+	}
+	if len(fields) == 3 && fields[1] == "-" {
+		return parseHyphen(fields[0], fields[2])
+	}
+	set := make([]comparator, 0, len(fields))
+	for i := 0; i < len(fields); i++ {
+		tok := fields[i]
+		// Allow a space between the operator and the version: ">= 18".
+		if strings.Trim(tok, "<>=^~") == "" && i+1 < len(fields) {
+			i++
+			tok += fields[i]
+		}
+		cs, err := parseComparator(tok)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, cs...)
+	}
+	return set, nil
+}
+
+// parseHyphen expands "A - B" to an inclusive range. A partial upper bound
+// includes every version it prefixes: "1.2 - 2.3" is >=1.2.0 <2.4.0.
+func parseHyphen(from, to string) ([]comparator, error) {
+	lo, err := parsePartial(from)
+	if err != nil {
+		return nil, err
+	}
+	hi, err := parsePartial(to)
+	if err != nil {
+		return nil, err
+	}
+	set := make([]comparator, 0, 2)
+	if lo.parts > 0 {
+		set = append(set, comparator{opGE, release(lo)})
+	}
+	switch {
+	case hi.parts == 3:
+		set = append(set, comparator{opLE, release(hi)})
+	case hi.parts > 0:
+		set = append(set, comparator{opLT, bump(hi, hi.parts)})
+	}
+	if len(set) == 0 {
+		set = append(set, anyVersion)
+	}
+	return set, nil
+}
+
+// parseComparator expands one token into the primitive comparators it
+// stands for.
+func parseComparator(tok string) ([]comparator, error) {
+	op := tok[:len(tok)-len(strings.TrimLeft(tok, "<>=^~"))]
+	p, err := parsePartial(tok[len(op):])
+	if err != nil {
+		return nil, err
+	}
+	lower := release(p)
+	if p.parts == 0 {
+		switch op {
+		case ">", "<":
+			return []comparator{noVersion}, nil
+		case "", "=", ">=", "<=", "^", "~", "~>":
+			return []comparator{anyVersion}, nil
+		}
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+	switch op {
+	case "^":
+		return []comparator{{opGE, lower}, {opLT, caretUpper(p)}}, nil
+	case "~", "~>":
+		return []comparator{{opGE, lower}, {opLT, tildeUpper(p)}}, nil
+	case "", "=":
+		if p.parts == 3 {
+			return []comparator{{opEQ, lower}}, nil
+		}
+		return []comparator{{opGE, lower}, {opLT, bump(p, p.parts)}}, nil
+	case ">=":
+		return []comparator{{opGE, lower}}, nil
+	case ">":
+		if p.parts == 3 {
+			return []comparator{{opGT, lower}}, nil
+		}
+		return []comparator{{opGE, bump(p, p.parts)}}, nil
+	case "<":
+		return []comparator{{opLT, lower}}, nil
+	case "<=":
+		if p.parts == 3 {
+			return []comparator{{opLE, lower}}, nil
+		}
+		return []comparator{{opLT, bump(p, p.parts)}}, nil
+	}
+	return nil, fmt.Errorf("unknown operator %q", op)
+}
+
+// release drops the build metadata of p, which never affects matching.
+func release(p partial) Version {
+	v := p.Version
+	v.Build = nil
+	return v
+}
+
+// bump increments the component at position n (1 = major) of p and zeroes
+// the ones after it.
+func bump(p partial, n int) Version {
+	switch n {
+	case 1:
+		return Version{Major: p.Major + 1}
+	case 2:
+		return Version{Major: p.Major, Minor: p.Minor + 1}
+	default:
+		return Version{Major: p.Major, Minor: p.Minor, Patch: p.Patch + 1}
+	}
+}
+
+// caretUpper allows changes that do not modify the left-most non-zero
+// component: ^1.2.3 is <2.0.0, ^0.2.3 is <0.3.0 and ^0.0.3 is <0.0.4.
+func caretUpper(p partial) Version {
+	switch {
+	case p.Major > 0 || p.parts == 1:
+		return bump(p, 1)
+	case p.Minor > 0 || p.parts == 2:
+		return bump(p, 2)
+	default:
+		return bump(p, 3)
+	}
+}
+
+// tildeUpper allows patch-level changes when a minor version is given and
+// minor-level changes otherwise: ~1.2.3 is <1.3.0 and ~1 is <2.0.0.
+func tildeUpper(p partial) Version {
+	if p.parts == 1 {
+		return bump(p, 1)
+	}
+	return bump(p, 2)
+}
@@ -0,0 +1,215 @@
+// Package semver parses semantic versions and evaluates version ranges in
+// the syntax used by package.json and node-semver.
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version. Build metadata is kept for display but
+// ignored when comparing.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease []string
+	Build      []string
+}
+
+// Parse parses a full version such as "20.11.1", "v18.17.0" or
+// "9.0.0-rc.1+build.5".
+func Parse(s string) (Version, error) {
+	p, err := parsePartial(s)
+	if err != nil {
+		return Version{}, err
+	}
+	if p.parts < 3 {
+		return Version{}, fmt.Errorf("invalid version %q: expected major.minor.patch", s)
+	}
+	return p.Version, nil
+}
+
+// MustParse is like Parse but panics on error. It is meant for constants.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if len(v.Prerelease) > 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if len(v.Build) > 0 {
+		s += "+" + strings.Join(v.Build, ".")
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 depending on whether v sorts before, equal to
+// or after o. A prerelease sorts before its release.
+func (v Version) Compare(o Version) int {
+	for _, c := range [][2]uint64{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		if c[0] != c[1] {
+			if c[0] < c[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return comparePrerelease(v.Prerelease, o.Prerelease)
+}
+
+func (v Version) LessThan(o Version) bool {
+	return v.Compare(o) < 0
+}
+
+func comparePrerelease(a, b []string) int {
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 0
+	case len(a) == 0:
+		return 1
+	case len(b) == 0:
+		return -1
+	}
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := compareIdentifier(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+	switch {
+	case len(a) < len(b):
+		return -1
+	case len(a) > len(b):
+		return 1
+	}
+	return 0
+}
+
+// compareIdentifier orders numeric identifiers numerically and before
+// alphanumeric ones, which are ordered lexically.
+func compareIdentifier(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		if na == nb {
+			return 0
+		}
+		if na < nb {
+			return -1
+		}
+		return 1
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// partial is a version that may omit trailing components or replace them
+// with a wildcard (x, X or *), as in the range "^20.11", "1.2.x" or an
+// .nvmrc containing "20". parts counts the components that are given.
+type partial struct {
+	Version
+	parts int
+}
+
+func parsePartial(s string) (partial, error) {
+	raw := s
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "=")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "v"), "V")
+	var p partial
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		p.Build = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		p.Prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	for _, id := range append(append([]string{}, p.Prerelease...), p.Build...) {
+		if !validIdentifier(id) {
+			return partial{}, fmt.Errorf("invalid version %q", raw)
+		}
+	}
+	fields := strings.Split(s, ".")
+	if s == "" || len(fields) > 3 {
+		return partial{}, fmt.Errorf("invalid version %q", raw)
+	}
+	nums := []*uint64{&p.Major, &p.Minor, &p.Patch}
+	for i, f := range fields {
+		if isWildcard(f) {
+			// Everything after a wildcard must be a wildcard too.
+			for _, rest := range fields[i:] {
+				if !isWildcard(rest) {
+					return partial{}, fmt.Errorf("invalid version %q", raw)
+				}
+			}
+			break
+		}
+		n, err := strconv.ParseUint(f, 10, 64)
+		if err != nil || (len(f) > 1 && f[0] == '0') {
+			return partial{}, fmt.Errorf("invalid version %q", raw)
+		}
+		*nums[i] = n
+		p.parts++
+	}
+	if p.parts < 3 && (len(p.Prerelease) > 0 || len(p.Build) > 0) {
+		return partial{}, fmt.Errorf("invalid version %q", raw)
+	}
+	return p, nil
+}
+
+func isWildcard(s string) bool {
+	return s == "x" || s == "X" || s == "*"
+}
+
+func validIdentifier(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r == '-' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			return false
+		}
+	}
+	return true
+}
+
+var looseVersion = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.(\d+)(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?)?`)
+
+// Coerce extracts the first version-like number from free-form output such
+// as "git version 2.43.0" or "psql (PostgreSQL) 16.2", filling missing
+// components with zero. The prerelease of a full version is kept, so
+// "10.0.0-rc.1" does not pass for 10.0.0; build metadata is dropped.
+func Coerce(s string) (Version, error) {
+	m := looseVersion.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, fmt.Errorf("no version found in %q", s)
+	}
+	var v Version
+	for i, dst := range []*uint64{&v.Major, &v.Minor, &v.Patch} {
+		if m[i+1] == "" {
+			break
+		}
+		n, err := strconv.ParseUint(m[i+1], 10, 64)
+		if err != nil {
+			return Version{}, fmt.Errorf("no version found in %q", s)
+		}
+		*dst = n
+	}
+	if m[4] != "" {
+		v.Prerelease = strings.Split(m[4], ".")
+	}
+	return v, nil
+}
@@ -0,0 +1,164 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "20.11.1", want: "20.11.1"},
+		{in: "v18.17.0", want: "18.17.0"},
+		{in: "9.0.0-rc.1+build.5", want: "9.0.0-rc.1+build.5"},
+		{in: "18", wantErr: true},
+		{in: "1.02.3", wantErr: true},
+		{in: "1.2.3-", wantErr: true},
+		{in: "one.two.three", wantErr: true},
+	}
+	for _, tt := range tests {
+		v, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if err == nil && v.String() != tt.want {
+			t.Fatalf("Parse(%q) = %s, want %s", tt.in, v, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.10.0", "2.0.0",
+	}
+	for i := 1; i < len(ordered); i++ {
+		a, b := MustParse(ordered[i-1]), MustParse(ordered[i])
+		if a.Compare(b) != -1 || b.Compare(a) != 1 {
+			t.Fatalf("expected %s < %s", a, b)
+		}
+	}
+	if MustParse("1.0.0+a").Compare(MustParse("1.0.0+b")) != 0 {
+		t.Fatal("build metadata must not affect ordering")
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	tests := []struct {
+		rng     string
+		version string
+		want    bool
+	}{
+		{">=18.17 <21", "18.17.0", true},
+		{">=18.17 <21", "18.16.1", false},
+		{">=18.17 <21", "20.99.0", true},
+		{">=18.17 <21", "21.0.0", false},
+		{">= 18", "22.1.0", true},
+		{"^20.11", "20.11.0", true},
+		{"^20.11", "20.18.2", true},
+		{"^20.11", "20.10.9", false},
+		{"^20.11", "21.0.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~1", "1.9.0", true},
+		{"20", "20.11.1", true},
+		{"20", "21.0.0", false},
+		{"20.11", "20.12.0", false},
+		{"v20.11.1", "20.11.1", true},
+		{"=20.11.1", "20.11.2", false},
+		{">20", "20.5.0", false},
+		{">20", "21.0.0", true},
+		{"<=20.11", "20.11.5", true},
+		{"<=20.11", "20.12.0", false},
+		{"", "1.0.0", true},
+		{"*", "0.1.0", true},
+		{"1.2.x", "1.2.9", true},
+		{"1.2.x", "1.3.0", false},
+		{"1.X", "1.9.9", true},
+		{">=1.x", "1.0.0", true},
+		{"<1.x", "0.9.0", true},
+		{"<1.x", "1.0.0", false},
+		{">1.x", "2.0.0", true},
+		{">1.x", "1.9.0", false},
+		{"^1.x", "1.8.0", true},
+		{"~1.2.x", "1.2.5", true},
+		{">*", "1.0.0", false},
+		{"1.2.3 - 2.3.4", "2.3.4", true},
+		{"1.2.3 - 2.3.4", "2.3.5", false},
+		{"1.2.3 - 2.3.4", "1.2.2", false},
+		{"1.2 - 2.3", "2.3.9", true},
+		{"1.2 - 2.3", "2.4.0", false},
+		{"1.2.3 - 2", "2.99.0", true},
+		{"* - 2", "0.0.1", true},
+		{"^18.18 || ^20.9 || >=21", "18.19.0", true},
+		{"^18.18 || ^20.9 || >=21", "19.0.0", false},
+		{"^18.18 || ^20.9 || >=21", "20.8.0", false},
+		{"^18.18 || ^20.9 || >=21", "22.0.0", true},
+		{"14 || 16", "16.20.2", true},
+		{">=2", "3.0.0-rc.1", false},
+		{">=3.0.0-beta", "3.0.0-rc.1", true},
+		{">=3.0.0-beta", "3.0.1-rc.1", false},
+		{"^1.2.3-beta.2", "1.2.3-beta.4", true},
+		{"^1.2.3-beta.2", "1.2.4-beta.1", false},
+		{"^1.2.3-beta.2", "1.9.0", true},
+		{"1.2.3-alpha || 2", "1.2.3-alpha", true},
+		{"*", "1.0.0-rc.1", false},
+	}
+	for _, tt := range tests {
+		r, err := ParseRange(tt.rng)
+		if err != nil {
+			t.Fatalf("ParseRange(%q) error = %v", tt.rng, err)
+		}
+		if got := r.Contains(MustParse(tt.version)); got != tt.want {
+			t.Fatalf("%q contains %s = %v, want %v", tt.rng, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParseRangeErrors(t *testing.T) {
+	for _, s := range []string{">=abc", "=>18", "^", "18.x.y", "1.x.3", "1.2.3 - ", "1 || >=x.y"} {
+		if _, err := ParseRange(s); err == nil {
+			t.Fatalf("ParseRange(%q) expected error", s)
+		}
+	}
+}
+
+func TestOr(t *testing.T) {
+	r := Or(MustParseRange("^18"), MustParseRange("^20"))
+	if !r.Contains(MustParse("20.1.0")) || r.Contains(MustParse("19.0.0")) {
+		t.Fatalf("unexpected result for %s", r)
+	}
+	if r.String() != "^18 || ^20" {
+		t.Fatalf("String() = %q", r.String())
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	tests := map[string]string{
+		"git version 2.43.0":      "2.43.0",
+		"psql (PostgreSQL) 16.2":  "16.2.0",
+		"Python 3.12.1":           "3.12.1",
+		"GNU Make 4.3":            "4.3.0",
+		"v20.11.1\n":              "20.11.1",
+		"Docker version 27.0.3, ": "27.0.3",
+		"pnpm 9":                  "9.0.0",
+		"10.0.0-rc.1+sha.5d3e":    "10.0.0-rc.1",
+		"v22.0.0-nightly2024\n":   "22.0.0-nightly2024",
+		"pnpm 9-beta":             "9.0.0",
+	}
+	for in, want := range tests {
+		v, err := Coerce(in)
+		if err != nil {
+			t.Fatalf("Coerce(%q) error = %v", in, err)
+		}
+		if v.String() != want {
+			t.Fatalf("Coerce(%q) = %s, want %s", in, v, want)
+		}
+	}
+	if _, err := Coerce("command not found"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,240 @@
+package system
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"ilaunch/internal/pathenv"
+	"ilaunch/internal/system/semver"
+)
+
+// Toolchain is a node installation managed by a version manager.
+type Toolchain struct {
+	// Manager is the version manager that installed it, e.g. "fnm".
+	Manager string
+	Version semver.Version
+	// BinDir holds node, npm and corepack of this installation.
+	BinDir string
+}
+
+func (t Toolchain) String() string {
+	return fmt.Sprintf("node %s (%s)", t.Version, t.Manager)
+}
+
+// Env returns base with BinDir put first on PATH, so node and the package
+// managers bundled with it win over the system ones.
+func (t Toolchain) Env(base []string) []string {
+	return pathenv.Prepend(base, t.BinDir)
+}
+
+// VersionManager is a node version manager found on the machine. Each
+// installed version lives in its own directory under Root.
+type VersionManager struct {
+	Name string
+	Root string
+	// versions is the path from Root to the version directories and bin the
+	// path from a version directory to its bin directory.
+	versions string
+	bin      string
+	install  func(root, version string) []string
+}
+
+// versionManagers describes where each supported manager keeps its
+// installations. Roots are resolved from the manager's environment
+// variable, then its default locations.
+var versionManagers = []struct {
+	name     string
+	binary   string // executable that proves the manager is installed; "" for nvm
+	envVar   string
+	defaults []string // relative to $HOME
+	versions string
+	bin      string
+	install  func(root, version string) []string
+}{
+	{
+		name: "nvm", envVar: "NVM_DIR", defaults: []string{".nvm"},
+		versions: "versions/node", bin: "bin",
+		// nvm is a shell function, so it has to be sourced first.
+		install: func(root, version string) []string {
+			return []string{"bash", "-c", `. "$0/nvm.sh" && nvm install "$1"`, root, version}
+		},
+	},
+	{
+		name: "fnm", binary: "fnm", envVar: "FNM_DIR", defaults: []string{".local/share/fnm", ".fnm", "Library/Application Support/fnm"},
+		versions: "node-versions", bin: "installation/bin",
+		install: func(_, version string) []string { return []string{"fnm", "install", version} },
+	},
+	{
+		name: "volta", binary: "volta", envVar: "VOLTA_HOME", defaults: []string{".volta"},
+		versions: "tools/image/node", bin: "bin",
+		// fetch downloads without changing the user's default node.
+		install: func(_, version string) []string { return []string{"volta", "fetch", "node@" + version} },
+	},
+	{
+		name: "asdf", binary: "asdf", envVar: "ASDF_DATA_DIR", defaults: []string{".asdf"},
+		versions: "installs/nodejs", bin: "bin",
+		install: func(_, version string) []string { return []string{"asdf", "install", "nodejs", "latest:" + version} },
+	},
+	{
+		name: "mise", binary: "mise", envVar: "MISE_DATA_DIR", defaults: []string{".local/share/mise"},
+		versions: "installs/node", bin: "bin",
+		install: func(_, version string) []string { return []string{"mise", "install", "node@" + version} },
+	},
+}
+
+// DetectVersionManagers returns the node version managers available on the
+// machine.
+func DetectVersionManagers(commander Commander) []VersionManager {
+	home, _ := os.UserHomeDir()
+	return detectVersionManagers(commander, os.Getenv, home)
+}
+
+func detectVersionManagers(commander Commander, getenv func(string) string, home string) []VersionManager {
+	found := make([]VersionManager, 0)
+	for _, vm := range versionManagers {
+		if vm.binary != "" {
+			if _, err := commander.LookPath(vm.binary); err != nil {
+				continue
+			}
+		}
+		candidates := make([]string, 0, len(vm.defaults)+1)
+		if dir := getenv(vm.envVar); dir != "" {
+			candidates = append(candidates, dir)
+		}
+		for _, d := range vm.defaults {
+			candidates = append(candidates, filepath.Join(home, d))
+		}
+		root := ""
+		for _, c := range candidates {
+			if isDir(c) {
+				root = c
+				break
+			}
+		}
+		if vm.binary == "" && (root == "" || !isFile(filepath.Join(root, "nvm.sh"))) {
+			continue
+		}
+		if root == "" {
+			root = candidates[0]
+		}
+		found = append(found, VersionManager{Name: vm.name, Root: root, versions: vm.versions, bin: vm.bin, install: vm.install})
+	}
+	return found
+}
+
+// Installed lists the node versions installed by the manager. Directories
+// that are not plain versions, such as aliases, are skipped.
+func (vm VersionManager) Installed() ([]Toolchain, error) {
+	dir := filepath.Join(vm.Root, vm.versions)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("list %s node versions: %w", vm.Name, err)
+	}
+	toolchains := make([]Toolchain, 0, len(entries))
+	for _, e := range entries {
+		v, err := semver.Parse(e.Name())
+		if err != nil {
+			continue
+		}
+		bin := filepath.Join(dir, e.Name(), vm.bin)
+		if !isDir(bin) {
+			continue
+		}
+		toolchains = append(toolchains, Toolchain{Manager: vm.Name, Version: v, BinDir: bin})
+	}
+	return toolchains, nil
+}
+
+// InstallCommand returns the command that installs node through the
+// manager. version is an exact version or a major line, as returned by
+// InstallTarget.
+func (vm VersionManager) InstallCommand(version string) []string {
+	return vm.install(vm.Root, version)
+}
+
+// SatisfyingToolchains returns the installed toolchains that meet every
+// constraint, newest first.
+func SatisfyingToolchains(managers []VersionManager, constraints []NodeConstraint) ([]Toolchain, error) {
+	matches := make([]Toolchain, 0)
+	for _, vm := range managers {
+		installed, err := vm.Installed()
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range installed {
+			if SatisfiesAll(t.Version, constraints) {
+				matches = append(matches, t)
+			}
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b Toolchain) int {
+		return b.Version.Compare(a.Version)
+	})
+	return matches, nil
+}
+
+// InstallTarget picks the node version to install for constraints: a
+// version pinned exactly, such as "20.11.1" in .nvmrc, else the newest LTS
+// line whose releases satisfy them, else the newest such major, else the
+// newest satisfying minor line, such as "20.11" for "~20.11.0".
+func InstallTarget(constraints []NodeConstraint) (string, bool) {
+	for _, c := range constraints {
+		if v, err := semver.Parse(c.Spec); err == nil && SatisfiesAll(v, constraints) {
+			return v.String(), true
+		}
+	}
+	majors := make([]uint64, 0, len(ltsMajors))
+	for _, m := range ltsMajors {
+		majors = append(majors, m)
+	}
+	slices.SortFunc(majors, func(a, b uint64) int { return cmp.Compare(b, a) })
+	for _, m := range majors {
+		if majorSatisfies(m, constraints) {
+			return fmt.Sprint(m), true
+		}
+	}
+	for m := majors[0] + 1; m > 0; m-- {
+		if majorSatisfies(m, constraints) {
+			return fmt.Sprint(m), true
+		}
+	}
+	for m := majors[0] + 1; m > 0; m-- {
+		for minor := uint64(maxMinor); ; minor-- {
+			if SatisfiesAll(semver.Version{Major: m, Minor: minor, Patch: 999}, constraints) {
+				return fmt.Sprintf("%d.%d", m, minor), true
+			}
+			if minor == 0 {
+				break
+			}
+		}
+	}
+	return "", false
+}
+
+// maxMinor bounds the minor lines InstallTarget probes; node majors stay
+// well below it.
+const maxMinor = 99
+
+// majorSatisfies checks a late release of the major line, which is what a
+// version manager installs for it.
+func majorSatisfies(major uint64, constraints []NodeConstraint) bool {
+	return SatisfiesAll(semver.Version{Major: major, Minor: 999, Patch: 999}, constraints)
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,98 @@
+package system
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func mkdirs(t *testing.T, root string, dirs ...string) {
+	t.Helper()
+	for _, d := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, d), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetectVersionManagers(t *testing.T) {
+	home := t.TempDir()
+	mkdirs(t, home,
+		".nvm/versions/node/v18.19.0/bin",
+		".nvm/versions/node/v20.11.1/bin",
+		"fnm-custom/node-versions/v22.2.0/installation/bin",
+		"fnm-custom/node-versions/.downloads",
+		".volta/tools/image/node/16.20.2/bin",
+	)
+	if err := os.WriteFile(filepath.Join(home, ".nvm/nvm.sh"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	env := map[string]string{"FNM_DIR": filepath.Join(home, "fnm-custom")}
+	commander := fakeCommander{paths: map[string]string{"fnm": "/bin/fnm", "volta": "/bin/volta", "mise": "/bin/mise"}}
+	managers := detectVersionManagers(commander, func(k string) string { return env[k] }, home)
+
+	names := make([]string, 0, len(managers))
+	for _, m := range managers {
+		names = append(names, m.Name)
+	}
+	if got := strings.Join(names, ","); got != "nvm,fnm,volta,mise" {
+		t.Fatalf("managers = %s", got)
+	}
+
+	rng, _, err := parseNodeSpec(">=18.17 <21 || >=22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	constraints := []NodeConstraint{{Source: "test", Spec: ">=18.17 <21 || >=22", Range: rng}}
+	toolchains, err := SatisfyingToolchains(managers, constraints)
+	if err != nil {
+		t.Fatalf("SatisfyingToolchains() error = %v", err)
+	}
+	got := make([]string, 0, len(toolchains))
+	for _, tc := range toolchains {
+		got = append(got, tc.String())
+	}
+	want := "node 22.2.0 (fnm),node 20.11.1 (nvm),node 18.19.0 (nvm)"
+	if strings.Join(got, ",") != want {
+		t.Fatalf("toolchains = %s, want %s", strings.Join(got, ","), want)
+	}
+	if bin := toolchains[0].BinDir; bin != filepath.Join(home, "fnm-custom/node-versions/v22.2.0/installation/bin") {
+		t.Fatalf("BinDir = %s", bin)
+	}
+}
+
+func TestToolchainEnv(t *testing.T) {
+	tc := Toolchain{BinDir: "/opt/node/bin"}
+	env := tc.Env([]string{"HOME=/home/me", "PATH=/usr/bin"})
+	want := "HOME=/home/me PATH=/opt/node/bin" + string(os.PathListSeparator) + "/usr/bin"
+	if got := strings.Join(env, " "); got != want {
+		t.Fatalf("Env() = %q, want %q", got, want)
+	}
+}
+
+func TestInstallTarget(t *testing.T) {
+	constraint := func(spec string) []NodeConstraint {
+		rng, _, err := parseNodeSpec(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []NodeConstraint{{Source: "test", Spec: spec, Range: rng}}
+	}
+	tests := map[string]string{
+		"20.11.1":      "20.11.1",
+		"v18.19.0":     "18.19.0",
+		">=18.17 <21":  "20",
+		"^21.2":        "21",
+		"lts/hydrogen": "18",
+		"20.11":        "20.11",
+		"~20.11.0":     "20.11",
+		">=30":         "",
+	}
+	for spec, want := range tests {
+		got, ok := InstallTarget(constraint(spec))
+		if got != want || ok != (want != "") {
+			t.Fatalf("InstallTarget(%q) = %q, %v; want %q", spec, got, ok, want)
+		}
+	}
+}
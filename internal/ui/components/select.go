@@ -0,0 +1,113 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Field is an editable form control.
+type Field interface {
+	Value() string
+	Update(tea.KeyMsg)
+	View() string
+	ViewFocused() string
+}
+
+// emptyLabel is shown for the empty option of a select.
+const emptyLabel = "(empty)"
+
+var selectedStyle = lipgloss.NewStyle().Reverse(true)
+
+// Select picks one of a fixed list of options. Left/right and space cycle
+// through them, and typing a letter jumps to the next option starting with
+// it. With two options it behaves as a toggle.
+type Select struct {
+	Options []string
+	index   int
+}
+
+// NewSelect selects value, adding it as an option when it is empty and not
+// listed, so an optional field can stay unset. Any other value that is not
+// one of options is an error rather than a silent switch to the first one.
+func NewSelect(options []string, value string) (Select, error) {
+	s := Select{Options: options}
+	for i, o := range options {
+		if o == value {
+			s.index = i
+			return s, nil
+		}
+	}
+	if value != "" {
+		return Select{}, fmt.Errorf("%q is not one of %s", value, strings.Join(options, ", "))
+	}
+	s.Options = append([]string{""}, options...)
+	return s, nil
+}
+
+func (s Select) Value() string {
+	if len(s.Options) == 0 {
+		return ""
+	}
+	return s.Options[s.index]
+}
+
+func (s *Select) Update(k tea.KeyMsg) {
+	n := len(s.Options)
+	if n == 0 {
+		return
+	}
+	switch k.String() {
+	case "left":
+		s.index = (s.index + n - 1) % n
+	case "right", " ":
+		s.index = (s.index + 1) % n
+	case "home":
+		s.index = 0
+	case "end":
+		s.index = n - 1
+	default:
+		if k.Type == tea.KeyRunes && len(k.Runes) == 1 {
+			s.jump(k.Runes[0])
+		}
+	}
+}
+
+// jump selects the next option after the current one that starts with r.
+func (s *Select) jump(r rune) {
+	prefix := strings.ToLower(string(r))
+	for step := 1; step <= len(s.Options); step++ {
+		i := (s.index + step) % len(s.Options)
+		if strings.HasPrefix(strings.ToLower(s.Options[i]), prefix) {
+			s.index = i
+			return
+		}
+	}
+}
+
+// View renders the selected option.
+func (s Select) View() string {
+	return optionLabel(s.Value())
+}
+
+// ViewFocused renders every option with the selected one highlighted.
+func (s Select) ViewFocused() string {
+	parts := make([]string, 0, len(s.Options))
+	for i, o := range s.Options {
+		if i == s.index {
+			parts = append(parts, selectedStyle.Render(" "+optionLabel(o)+" "))
+			continue
+		}
+		parts = append(parts, " "+optionLabel(o)+" ")
+	}
+	return "‹" + strings.Join(parts, "") + "›"
+}
+
+func optionLabel(o string) string {
+	if o == "" {
+		return emptyLabel
+	}
+	return o
+}
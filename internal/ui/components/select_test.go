@@ -0,0 +1,53 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSelect(t *testing.T) {
+	s, err := NewSelect([]string{"development", "production", "test"}, "production")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Value(); got != "production" {
+		t.Fatalf("initial Value() = %q", got)
+	}
+	s.Update(tea.KeyMsg{Type: tea.KeyRight})
+	s.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if got := s.Value(); got != "development" {
+		t.Fatalf("right should wrap around, got %q", got)
+	}
+	s.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if got := s.Value(); got != "test" {
+		t.Fatalf("typing t should jump to test, got %q", got)
+	}
+	s.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if got := s.Value(); got != "production" {
+		t.Fatalf("left = %q", got)
+	}
+}
+
+func TestSelectKeepsEmptyValue(t *testing.T) {
+	s, err := NewSelect([]string{"true", "false"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Value(); got != "" {
+		t.Fatalf("Value() = %q, want empty", got)
+	}
+	if got := s.View(); got != emptyLabel {
+		t.Fatalf("View() = %q", got)
+	}
+	s.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if got := s.Value(); got != "true" {
+		t.Fatalf("space should select the next option, got %q", got)
+	}
+}
+
+func TestSelectRejectsUnknownValue(t *testing.T) {
+	if _, err := NewSelect([]string{"development", "production"}, "staging"); err == nil {
+		t.Fatal("expected an error for a value that is not an option")
+	}
+}
@@ -0,0 +1,151 @@
+package components
+
+import (
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// MaskBullet is drawn for every character of a masked input.
+const MaskBullet = "•"
+
+// MaskedValue replaces a secret value wherever it is displayed outside of
+// its input, hiding its length too.
+const MaskedValue = "••••"
+
+// newlineGlyph stands in for line breaks of pasted multi-line values.
+const newlineGlyph = "↵"
+
+var cursorStyle = lipgloss.NewStyle().Reverse(true)
+
+// TextInput is a single-line text field with a cursor. It edits runes, not
+// bytes, so multi-byte characters are never split. When Masked is set the
+// value is rendered as bullets so secrets never appear on screen.
+type TextInput struct {
+	Masked bool
+	value  []rune
+	cursor int
+}
+
+func NewTextInput(value string, masked bool) TextInput {
+	t := TextInput{Masked: masked}
+	t.SetValue(value)
+	return t
+}
+
+func (t TextInput) Value() string {
+	return string(t.value)
+}
+
+// SetValue replaces the value and moves the cursor to its end.
+func (t *TextInput) SetValue(value string) {
+	t.value = []rune(value)
+	t.cursor = len(t.value)
+}
+
+func (t *TextInput) Update(k tea.KeyMsg) {
+	switch k.String() {
+	case "left", "ctrl+b":
+		t.cursor = max(t.cursor-1, 0)
+	case "right", "ctrl+f":
+		t.cursor = min(t.cursor+1, len(t.value))
+	case "home", "ctrl+a":
+		t.cursor = 0
+	case "end", "ctrl+e":
+		t.cursor = len(t.value)
+	case "alt+left", "alt+b":
+		t.cursor = t.wordStart()
+	case "alt+right", "alt+f":
+		t.cursor = t.wordEnd()
+	case "backspace", "ctrl+h":
+		if t.cursor > 0 {
+			t.deleteRange(t.cursor-1, t.cursor)
+		}
+	case "delete", "ctrl+d":
+		if t.cursor < len(t.value) {
+			t.deleteRange(t.cursor, t.cursor+1)
+		}
+	case "ctrl+w", "alt+backspace":
+		t.deleteRange(t.wordStart(), t.cursor)
+	case "alt+d":
+		t.deleteRange(t.cursor, t.wordEnd())
+	case "ctrl+u":
+		t.deleteRange(0, t.cursor)
+	case "ctrl+k":
+		t.deleteRange(t.cursor, len(t.value))
+	default:
+		if k.Type == tea.KeyRunes || k.Type == tea.KeySpace {
+			t.insert(k.Runes)
+		}
+	}
+}
+
+func (t *TextInput) insert(runes []rune) {
+	text := strings.ReplaceAll(string(runes), "\r\n", "\n")
+	ins := []rune(strings.ReplaceAll(text, "\r", "\n"))
+	t.value = append(t.value[:t.cursor], append(ins, t.value[t.cursor:]...)...)
+	t.cursor += len(ins)
+}
+
+func (t *TextInput) deleteRange(from, to int) {
+	if from >= to {
+		return
+	}
+	t.value = append(t.value[:from], t.value[to:]...)
+	t.cursor = from
+}
+
+// wordStart returns the position of the start of the word before the cursor.
+func (t TextInput) wordStart() int {
+	i := t.cursor
+	for i > 0 && !isWordRune(t.value[i-1]) {
+		i--
+	}
+	for i > 0 && isWordRune(t.value[i-1]) {
+		i--
+	}
+	return i
+}
+
+// wordEnd returns the position of the end of the word after the cursor.
+func (t TextInput) wordEnd() int {
+	i := t.cursor
+	for i < len(t.value) && !isWordRune(t.value[i]) {
+		i++
+	}
+	for i < len(t.value) && isWordRune(t.value[i]) {
+		i++
+	}
+	return i
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// View renders the value without a cursor.
+func (t TextInput) View() string {
+	return t.render(t.value)
+}
+
+// ViewFocused renders the value with the cursor shown as a reversed cell.
+func (t TextInput) ViewFocused() string {
+	under := " "
+	if t.cursor < len(t.value) {
+		under = t.render(t.value[t.cursor : t.cursor+1])
+	}
+	after := ""
+	if t.cursor < len(t.value) {
+		after = t.render(t.value[t.cursor+1:])
+	}
+	return t.render(t.value[:t.cursor]) + cursorStyle.Render(under) + after
+}
+
+func (t TextInput) render(runes []rune) string {
+	if t.Masked {
+		return strings.Repeat(MaskBullet, len(runes))
+	}
+	return strings.ReplaceAll(string(runes), "\n", newlineGlyph)
+}
@@ -0,0 +1,61 @@
+package components
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func keys(t *TextInput, names ...string) {
+	for _, name := range names {
+		switch name {
+		case "left":
+			t.Update(tea.KeyMsg{Type: tea.KeyLeft})
+		case "home":
+			t.Update(tea.KeyMsg{Type: tea.KeyHome})
+		case "backspace":
+			t.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		case "delete":
+			t.Update(tea.KeyMsg{Type: tea.KeyDelete})
+		case "ctrl+w":
+			t.Update(tea.KeyMsg{Type: tea.KeyCtrlW})
+		case "ctrl+k":
+			t.Update(tea.KeyMsg{Type: tea.KeyCtrlK})
+		default:
+			t.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)})
+		}
+	}
+}
+
+func TestTextInputEditing(t *testing.T) {
+	cases := []struct {
+		name  string
+		start string
+		keys  []string
+		want  string
+	}{
+		{"backspace multi-byte", "héllo ü", []string{"backspace"}, "héllo "},
+		{"insert at cursor", "ac", []string{"left", "b"}, "abc"},
+		{"delete under cursor", "abc", []string{"home", "delete"}, "bc"},
+		{"word delete", "postgres://db host", []string{"ctrl+w"}, "postgres://db "},
+		{"word delete skips punctuation", "a-b--", []string{"ctrl+w"}, "a-"},
+		{"kill to end", "abcdef", []string{"left", "left", "ctrl+k"}, "abcd"},
+		{"paste normalizes line endings", "", []string{"a\r\nb"}, "a\nb"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := NewTextInput(tc.start, false)
+			keys(&in, tc.keys...)
+			if got := in.Value(); got != tc.want {
+				t.Fatalf("Value() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTextInputMaskedView(t *testing.T) {
+	in := NewTextInput("sécret", true)
+	if got := in.View(); got != "••••••" {
+		t.Fatalf("View() = %q", got)
+	}
+}